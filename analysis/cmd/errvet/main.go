@@ -0,0 +1,17 @@
+// Command errvet checks the use of github.com/mochi-c/errors, see the
+// analyzers of the parent directory. It runs as a vet tool:
+//
+//	go install github.com/mochi-c/errors/analysis/cmd/errvet@latest
+//	go vet -vettool=$(which errvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/mochi-c/errors/analysis/errorinfo"
+	"github.com/mochi-c/errors/analysis/errwrap"
+)
+
+func main() {
+	unitchecker.Main(errorinfo.Analyzer, errwrap.Analyzer)
+}
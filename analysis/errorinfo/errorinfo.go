@@ -0,0 +1,463 @@
+// Package errorinfo defines an Analyzer checking the ErrorInfo types of
+// github.com/mochi-c/errors, whose WhenError method is called with the
+// wrapped error, and with nil for the text of the layer alone.
+package errorinfo
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const modulePath = "github.com/mochi-c/errors"
+
+const doc = `check the WhenError methods of ErrorInfo types
+
+The errorinfo analyzer reports:
+  - WhenError methods never referring to cause, so the message of the
+    wrapped error is lost;
+  - WhenError methods using cause where it may be nil, as it is when the
+    text of the layer alone is asked for;
+  - GetErrorInfo, GetAllErrorInfo, GetOriginalErrorInfo and WithErrorInfo
+    calls with a pointer type when WhenError has a value receiver, or the
+    other way round, so the lookups never match what is attached.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "errorinfo",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// lookups are the functions finding the infos of type T attached by attaches.
+var (
+	lookups  = map[string]bool{"GetErrorInfo": true, "GetAllErrorInfo": true, "GetOriginalErrorInfo": true}
+	attaches = map[string]bool{"WithErrorInfo": true, "WithErrorInfoCapture": true}
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			checkWhenError(pass, n)
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+// whenErrorParam returns the cause parameter of decl if it declares a
+// WhenError(error) string method.
+func whenErrorParam(pass *analysis.Pass, decl *ast.FuncDecl) (*types.Var, bool) {
+	if decl.Recv == nil || decl.Name.Name != "WhenError" || decl.Body == nil {
+		return nil, false
+	}
+	fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 1 || sig.Results().Len() != 1 ||
+		!types.Identical(sig.Params().At(0).Type(), types.Universe.Lookup("error").Type()) ||
+		!types.Identical(sig.Results().At(0).Type(), types.Typ[types.String]) {
+		return nil, false
+	}
+	return sig.Params().At(0), true
+}
+
+// refersTo reports whether n refers to v.
+func refersTo(pass *analysis.Pass, n ast.Node, v *types.Var) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == v {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func checkWhenError(pass *analysis.Pass, decl *ast.FuncDecl) {
+	cause, ok := whenErrorParam(pass, decl)
+	if !ok {
+		return
+	}
+	if cause.Name() == "" || cause.Name() == "_" || !refersTo(pass, decl.Body, cause) {
+		pass.Report(analysis.Diagnostic{
+			Pos:            decl.Name.Pos(),
+			Message:        "WhenError never refers to cause, so the message of the wrapped error is lost",
+			SuggestedFixes: appendCauseFix(pass, decl),
+		})
+		return
+	}
+	w := &nilCauseWalker{pass: pass, cause: cause, reported: make(map[ast.Stmt]bool)}
+	w.stmts(decl.Body.List, false)
+}
+
+// appendCauseFix returns a fix appending the message of cause to the text
+// of the layer when cause is not nil.
+func appendCauseFix(pass *analysis.Pass, decl *ast.FuncDecl) []analysis.SuggestedFix {
+	var edits []analysis.TextEdit
+	param := decl.Type.Params.List[0]
+	causeName := "cause"
+	switch {
+	case len(param.Names) == 0:
+		edits = append(edits, insert(param.Type.Pos(), causeName+" "))
+	case param.Names[0].Name == "_":
+		edits = append(edits, replace(param.Names[0], causeName))
+	default:
+		causeName = param.Names[0].Name
+	}
+
+	recv := decl.Recv.List[0]
+	var recvName string
+	if len(recv.Names) > 0 && recv.Names[0].Name != "_" {
+		recvName = recv.Names[0].Name
+	} else {
+		recvName = strings.ToLower(typeName(recv.Type)[:1])
+		if recvName == causeName {
+			recvName = "info"
+		}
+		if len(recv.Names) == 0 {
+			edits = append(edits, insert(recv.Type.Pos(), recvName+" "))
+		} else {
+			edits = append(edits, replace(recv.Names[0], recvName))
+		}
+	}
+
+	text := fmt.Sprintf("if %[1]s != nil {\n\t\treturn %[2]s.WhenError(nil) + \": \" + %[1]s.Error()\n\t}\n",
+		causeName, recvName)
+	if body := decl.Body; line(pass, body.Lbrace) == line(pass, body.Rbrace) || len(body.List) == 0 {
+		edits = append(edits, insert(body.Lbrace+1, "\n\t"+text))
+	} else {
+		// before the first statement, to keep the comments of the brace line
+		edits = append(edits, insert(body.List[0].Pos(), text+"\t"))
+	}
+	return []analysis.SuggestedFix{{
+		Message:   "Append the message of cause",
+		TextEdits: edits,
+	}}
+}
+
+// typeName returns the name of the receiver type expr.
+func typeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return "info"
+		}
+	}
+}
+
+// nilCauseWalker reports the selectors on cause evaluated where cause may
+// be nil, following the nil checks of cause.
+type nilCauseWalker struct {
+	pass     *analysis.Pass
+	cause    *types.Var
+	reported map[ast.Stmt]bool
+}
+
+func (w *nilCauseWalker) stmts(stmts []ast.Stmt, guarded bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.BlockStmt:
+			w.stmts(s.List, guarded)
+		case *ast.IfStmt:
+			if s.Init != nil {
+				w.scan(s, s.Init, guarded)
+			}
+			switch w.nilCheck(s.Cond) {
+			case token.NEQ:
+				w.stmts(s.Body.List, true)
+				w.elseStmt(s.Else, false)
+			case token.EQL:
+				w.stmts(s.Body.List, false)
+				w.elseStmt(s.Else, true)
+				if terminates(s.Body) {
+					guarded = true
+				}
+			default:
+				w.scan(s, s.Cond, guarded)
+				w.stmts(s.Body.List, guarded)
+				w.elseStmt(s.Else, guarded)
+			}
+		case *ast.SwitchStmt:
+			if s.Init != nil {
+				w.scan(s, s.Init, guarded)
+			}
+			if s.Tag != nil {
+				w.scan(s, s.Tag, guarded)
+				w.clauses(s.Body, guarded, false)
+			} else {
+				w.clauses(s.Body, guarded, true)
+			}
+		default:
+			w.scan(stmt, stmt, guarded)
+		}
+	}
+}
+
+func (w *nilCauseWalker) elseStmt(stmt ast.Stmt, guarded bool) {
+	if stmt != nil {
+		w.stmts([]ast.Stmt{stmt}, guarded)
+	}
+}
+
+// clauses walks the clauses of a switch, following the nil checks of its
+// cases when it has no tag: the clauses after case cause == nil run only
+// when cause is not nil.
+func (w *nilCauseWalker) clauses(body *ast.BlockStmt, guarded, tagless bool) {
+	for _, stmt := range body.List {
+		clause := stmt.(*ast.CaseClause)
+		clauseGuarded, nilCase := guarded, false
+		for _, expr := range clause.List {
+			switch check := w.nilCheck(expr); {
+			case tagless && check == token.NEQ && len(clause.List) == 1:
+				clauseGuarded = true
+			case tagless && check == token.EQL && len(clause.List) == 1:
+				nilCase = true
+			default:
+				w.scan(clause, expr, guarded)
+			}
+		}
+		w.stmts(clause.Body, clauseGuarded)
+		if nilCase {
+			guarded = true
+		}
+	}
+}
+
+// nilCheck returns the operator of expr if it compares cause with nil.
+func (w *nilCauseWalker) nilCheck(expr ast.Expr) token.Token {
+	bin, ok := ast.Unparen(expr).(*ast.BinaryExpr)
+	if !ok || bin.Op != token.EQL && bin.Op != token.NEQ {
+		return token.ILLEGAL
+	}
+	if w.isCause(bin.X) && isNil(w.pass, bin.Y) || isNil(w.pass, bin.X) && w.isCause(bin.Y) {
+		return bin.Op
+	}
+	return token.ILLEGAL
+}
+
+func (w *nilCauseWalker) isCause(expr ast.Expr) bool {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && w.pass.TypesInfo.Uses[id] == w.cause
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
+
+// terminates reports whether block ends with a return or a panic.
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch s := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		return ok && id.Name == "panic"
+	}
+	return false
+}
+
+// scan reports the selectors on cause of n, part of stmt, unless guarded.
+func (w *nilCauseWalker) scan(stmt ast.Stmt, n ast.Node, guarded bool) {
+	if guarded {
+		return
+	}
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SelectorExpr:
+			if w.isCause(n.X) && !w.reported[stmt] {
+				w.reported[stmt] = true
+				w.pass.Report(analysis.Diagnostic{
+					Pos: n.Pos(),
+					End: n.End(),
+					Message: fmt.Sprintf("%s.%s panics when cause is nil, as it is for the text of the layer alone",
+						w.cause.Name(), n.Sel.Name),
+					SuggestedFixes: w.nilReturnFix(stmt, n),
+				})
+			}
+		}
+		return true
+	})
+}
+
+// nilReturnFix returns a fix returning early when cause is nil, with the
+// text stmt returns before the message of cause when it has the form
+// return text + ": " + cause.Error().
+func (w *nilCauseWalker) nilReturnFix(stmt ast.Stmt, sel *ast.SelectorExpr) []analysis.SuggestedFix {
+	text := `""`
+	if ret, ok := stmt.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+		if bin, ok := ret.Results[0].(*ast.BinaryExpr); ok && bin.Op == token.ADD {
+			if call, ok := bin.Y.(*ast.CallExpr); ok && call.Fun == sel {
+				left := bin.X
+				if sep, ok := left.(*ast.BinaryExpr); ok && sep.Op == token.ADD {
+					if lit, ok := sep.Y.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						if s, _ := strconv.Unquote(lit.Value); strings.TrimSpace(s) == ":" {
+							left = sep.X
+						}
+					}
+				}
+				var buf bytes.Buffer
+				if err := format.Node(&buf, w.pass.Fset, left); err == nil {
+					text = buf.String()
+				}
+			}
+		}
+	}
+	indent := strings.Repeat("\t", w.pass.Fset.Position(stmt.Pos()).Column-1)
+	return []analysis.SuggestedFix{{
+		Message: "Return early when cause is nil",
+		TextEdits: []analysis.TextEdit{insert(stmt.Pos(),
+			fmt.Sprintf("if %s == nil {\n%s\treturn %s\n%s}\n%s", w.cause.Name(), indent, text, indent, indent))},
+	}}
+}
+
+// whenErrorReceiver reports whether the WhenError method of t, or of the
+// type t points to, has a pointer receiver.
+func whenErrorReceiver(t types.Type) (pointer, ok bool) {
+	if p, isPtr := t.(*types.Pointer); isPtr {
+		t = p.Elem()
+	}
+	named, isNamed := t.(*types.Named)
+	if !isNamed {
+		return false, false
+	}
+	if _, isIface := named.Underlying().(*types.Interface); isIface {
+		return false, false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(named, true, named.Obj().Pkg(), "WhenError")
+	fn, isFunc := obj.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	_, pointer = fn.Type().(*types.Signature).Recv().Type().(*types.Pointer)
+	return pointer, true
+}
+
+// checkCall reports the calls of the errors package whose type argument
+// has a pointer while WhenError has a value receiver, or the other way round.
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fun := ast.Unparen(call.Fun)
+	var index ast.Expr
+	if ix, ok := fun.(*ast.IndexExpr); ok {
+		fun, index = ix.X, ix.Index
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != modulePath {
+		return
+	}
+	inst, ok := pass.TypesInfo.Instances[id]
+	if !ok || inst.TypeArgs.Len() != 1 {
+		return
+	}
+	t := inst.TypeArgs.At(0)
+	pointerRecv, ok := whenErrorReceiver(t)
+	if !ok {
+		return
+	}
+	_, isPtr := t.(*types.Pointer)
+	qualifier := types.RelativeTo(pass.Pkg)
+	switch {
+	case lookups[fn.Name()] && pointerRecv && !isPtr:
+		var fixes []analysis.SuggestedFix
+		if index != nil {
+			fixes = []analysis.SuggestedFix{{
+				Message:   "Look up the pointer type",
+				TextEdits: []analysis.TextEdit{insert(index.Pos(), "*")},
+			}}
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos: call.Pos(),
+			Message: fmt.Sprintf("%s[%s] never matches: WhenError has a pointer receiver, so only *%[2]s is an ErrorInfo",
+				fn.Name(), types.TypeString(t, qualifier)),
+			SuggestedFixes: fixes,
+		})
+	case lookups[fn.Name()] && !pointerRecv && isPtr:
+		var fixes []analysis.SuggestedFix
+		if star, ok := index.(*ast.StarExpr); ok {
+			fixes = []analysis.SuggestedFix{{
+				Message:   "Look up the value type",
+				TextEdits: []analysis.TextEdit{{Pos: star.Pos(), End: star.X.Pos()}},
+			}}
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos: call.Pos(),
+			Message: fmt.Sprintf("%s[%s] misses the %s values attached: WhenError has a value receiver",
+				fn.Name(), types.TypeString(t, qualifier), types.TypeString(t.(*types.Pointer).Elem(), qualifier)),
+			SuggestedFixes: fixes,
+		})
+	case attaches[fn.Name()] && !pointerRecv && isPtr && len(call.Args) > 1:
+		info := call.Args[1]
+		var fixes []analysis.SuggestedFix
+		if addr, ok := ast.Unparen(info).(*ast.UnaryExpr); ok && addr.Op == token.AND {
+			fixes = []analysis.SuggestedFix{{
+				Message:   "Attach the value",
+				TextEdits: []analysis.TextEdit{{Pos: addr.Pos(), End: addr.X.Pos()}},
+			}}
+		}
+		elem := types.TypeString(t.(*types.Pointer).Elem(), qualifier)
+		pass.Report(analysis.Diagnostic{
+			Pos: info.Pos(),
+			Message: fmt.Sprintf("%s attaches a *%s while WhenError has a value receiver, so GetErrorInfo[%[2]s] misses it",
+				fn.Name(), elem),
+			SuggestedFixes: fixes,
+		})
+	}
+}
+
+func insert(pos token.Pos, text string) analysis.TextEdit {
+	return analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(text)}
+}
+
+func replace(n ast.Node, text string) analysis.TextEdit {
+	return analysis.TextEdit{Pos: n.Pos(), End: n.End(), NewText: []byte(text)}
+}
+
+func line(pass *analysis.Pass, pos token.Pos) int {
+	return pass.Fset.Position(pos).Line
+}
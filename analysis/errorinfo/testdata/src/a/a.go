@@ -0,0 +1,77 @@
+package a
+
+import (
+	"fmt"
+
+	"github.com/mochi-c/errors"
+)
+
+type Code int
+
+func (c Code) WhenError(cause error) string {
+	if cause != nil {
+		return fmt.Sprintf("code %d: %v", c, cause)
+	}
+	return fmt.Sprintf("code %d", c)
+}
+
+type Tag string
+
+func (Tag) WhenError(error) string { return "tag" } // want `WhenError never refers to cause, so the message of the wrapped error is lost`
+
+type Label struct{ Name string }
+
+func (l Label) WhenError(_ error) string { // want `WhenError never refers to cause, so the message of the wrapped error is lost`
+	return l.Name
+}
+
+type Op string
+
+func (o Op) WhenError(cause error) string {
+	return string(o) + ": " + cause.Error() // want `cause.Error panics when cause is nil, as it is for the text of the layer alone`
+}
+
+type Retry int
+
+func (r Retry) WhenError(cause error) string {
+	if cause == nil {
+		return "retry"
+	}
+	return fmt.Sprintf("retry %d: %s", r, cause.Error())
+}
+
+type Kind int
+
+func (k Kind) WhenError(cause error) string {
+	msg := cause.Error() // want `cause.Error panics when cause is nil, as it is for the text of the layer alone`
+	switch {
+	case cause == nil:
+		return "kind"
+	case k > 0:
+		return msg + cause.Error()
+	}
+	if cause != nil {
+		msg = cause.Error()
+	}
+	return msg
+}
+
+type Ptr struct{ ID int }
+
+func (p *Ptr) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	}
+	return fmt.Sprint(p.ID)
+}
+
+func lookups(err error) {
+	errors.GetErrorInfo[Code](err)
+	errors.GetErrorInfo[*Code](err)       // want `GetErrorInfo\[\*Code\] misses the Code values attached: WhenError has a value receiver`
+	errors.GetAllErrorInfo[Ptr](err)      // want `GetAllErrorInfo\[Ptr\] never matches: WhenError has a pointer receiver, so only \*Ptr is an ErrorInfo`
+	errors.GetOriginalErrorInfo[*Ptr](err)
+	errors.WithErrorInfo(err, &Ptr{ID: 1})
+	errors.WithErrorInfo(err, Label{})
+	errors.WithErrorInfo(err, &Label{}) // want `WithErrorInfo attaches a \*Label while WhenError has a value receiver, so GetErrorInfo\[Label\] misses it`
+	errors.GetErrorInfo[errors.ErrorInfo](err)
+}
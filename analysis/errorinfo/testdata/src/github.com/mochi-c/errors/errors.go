@@ -0,0 +1,14 @@
+// Package errors is a stub of github.com/mochi-c/errors.
+package errors
+
+type ErrorInfo interface {
+	WhenError(cause error) string
+}
+
+func WithErrorInfo[T ErrorInfo](err error, info T) error { return err }
+
+func GetErrorInfo[T any](err error) (res T, ok bool) { return res, false }
+
+func GetAllErrorInfo[T any](err error) []T { return nil }
+
+func GetOriginalErrorInfo[T any](err error) (res T, ok bool) { return res, false }
@@ -0,0 +1,351 @@
+// Package errwrap defines an Analyzer enforcing the rule of
+// github.com/mochi-c/errors: either handle an error, or wrap it with the
+// context of the call and return it, never log it and return it.
+package errwrap
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const modulePath = "github.com/mochi-c/errors"
+
+const doc = `check that errors are either handled or wrapped
+
+The errwrap analyzer reports:
+  - errors that are logged and then returned, so they end up logged twice;
+  - errors of other packages returned as-is by exported functions, without
+    Wrap or WithMessage adding the stack and the context of the call;
+  - WithMessagef calls given arguments while their format has no verb.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "errwrap",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// stdMethods are the methods of standard interfaces, whose errors are
+// returned as they are, such as io.EOF.
+var stdMethods = map[string]bool{
+	"Read": true, "ReadAt": true, "ReadByte": true, "ReadRune": true, "ReadFrom": true,
+	"Write": true, "WriteAt": true, "WriteByte": true, "WriteRune": true, "WriteString": true, "WriteTo": true,
+	"Seek": true, "Close": true, "Unwrap": true,
+	"MarshalJSON": true, "UnmarshalJSON": true, "MarshalText": true, "UnmarshalText": true,
+	"MarshalBinary": true, "UnmarshalBinary": true,
+}
+
+var (
+	errorType  = types.Universe.Lookup("error").Type()
+	errorIface = errorType.Underlying().(*types.Interface)
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkLogAndReturn(pass, n.Body)
+				checkBareReturns(pass, n)
+			}
+		case *ast.FuncLit:
+			checkLogAndReturn(pass, n.Body)
+		case *ast.CallExpr:
+			checkMessagef(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+// isErrorsPkg reports whether path is this module or one of its packages.
+func isErrorsPkg(path string) bool {
+	return path == modulePath || strings.HasPrefix(path, modulePath+"/")
+}
+
+func isError(t types.Type) bool {
+	return t != nil && types.Implements(t, errorIface)
+}
+
+// errorVar returns the error variable id refers to, if any.
+func errorVar(pass *analysis.Pass, id *ast.Ident) *types.Var {
+	v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || v.IsField() || !isError(v.Type()) {
+		return nil
+	}
+	return v
+}
+
+// refersTo reports whether n refers to v, outside of function literals.
+func refersTo(pass *analysis.Pass, n ast.Node, v *types.Var) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.Ident:
+			if pass.TypesInfo.Uses[n] == v {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// checkLogAndReturn reports the errors logged by a statement of body and
+// returned by a later statement of the same block.
+func checkLogAndReturn(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BlockStmt:
+			checkStmts(pass, n.List)
+		case *ast.CaseClause:
+			checkStmts(pass, n.Body)
+		case *ast.CommClause:
+			checkStmts(pass, n.Body)
+		}
+		return true
+	})
+}
+
+func checkStmts(pass *analysis.Pass, stmts []ast.Stmt) {
+	for i, stmt := range stmts {
+		call, v := loggedError(pass, stmt)
+		if v == nil {
+			continue
+		}
+		for _, next := range stmts[i+1:] {
+			if returns(pass, next, v) {
+				pass.Reportf(call.Pos(), "%s is logged and returned: handle it or wrap it, not both", v.Name())
+				break
+			}
+		}
+	}
+}
+
+// loggedError returns the logging call of stmt and the error it logs, if any.
+func loggedError(pass *analysis.Pass, stmt ast.Stmt) (*ast.CallExpr, *types.Var) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, nil
+	}
+	call, ok := ast.Unparen(expr.X).(*ast.CallExpr)
+	if !ok || !isLogCall(pass, call) {
+		return nil, nil
+	}
+	var logged *types.Var
+	for _, arg := range call.Args {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && logged == nil {
+				logged = errorVar(pass, id)
+			}
+			return logged == nil
+		})
+	}
+	return call, logged
+}
+
+// isLogCall reports whether call logs: it calls the log or log/slog
+// packages, or a method of a type named like a logger.
+func isLogCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	if path := fn.Pkg().Path(); path == "log" || path == "log/slog" {
+		return true
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && strings.HasSuffix(named.Obj().Name(), "Logger")
+}
+
+// returns reports whether stmt returns v, wrapped or not.
+func returns(pass *analysis.Pass, stmt ast.Stmt, v *types.Var) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, res := range n.Results {
+				if refersTo(pass, res, v) {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// foreignCallee returns the function call calls if it belongs to another
+// package, which is neither the one analyzed nor one of this module.
+func foreignCallee(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg() == pass.Pkg || isErrorsPkg(fn.Pkg().Path()) {
+		return nil
+	}
+	return fn
+}
+
+// checkBareReturns reports the returns of exported functions whose error
+// comes straight from a call of another package.
+// The packages of this module create the errors others wrap, and are not
+// checked.
+func checkBareReturns(pass *analysis.Pass, decl *ast.FuncDecl) {
+	if !decl.Name.IsExported() || isErrorsPkg(pass.Pkg.Path()) {
+		return
+	}
+	if decl.Recv != nil {
+		if stdMethods[decl.Name.Name] {
+			return
+		}
+		recv := decl.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if id, ok := recv.(*ast.Ident); ok && !id.IsExported() {
+			return
+		}
+	}
+	fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return
+	}
+	results := fn.Type().(*types.Signature).Results()
+	var errIndexes []int
+	for i := 0; i < results.Len(); i++ {
+		if types.Identical(results.At(i).Type(), errorType) {
+			errIndexes = append(errIndexes, i)
+		}
+	}
+	if len(errIndexes) == 0 {
+		return
+	}
+
+	// the foreign call each error variable was last assigned from, in
+	// source order
+	assigned := make(map[*types.Var]*types.Func)
+	assign := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, l := range lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			v := errorVar(pass, id)
+			if v == nil {
+				continue
+			}
+			var r ast.Expr
+			if len(rhs) == 1 {
+				r = rhs[0]
+			} else if i < len(rhs) {
+				r = rhs[i]
+			}
+			assigned[v] = nil
+			if call, ok := ast.Unparen(r).(*ast.CallExpr); ok {
+				assigned[v] = foreignCallee(pass, call)
+			}
+		}
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			assign(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			assign(lhs, n.Values)
+		case *ast.ReturnStmt:
+			if len(n.Results) == 1 && results.Len() > 1 {
+				// return f(), f returning every result
+				if call, ok := ast.Unparen(n.Results[0]).(*ast.CallExpr); ok {
+					reportBare(pass, decl, call, foreignCallee(pass, call))
+				}
+				return true
+			}
+			if len(n.Results) != results.Len() {
+				return true
+			}
+			for _, i := range errIndexes {
+				switch res := ast.Unparen(n.Results[i]).(type) {
+				case *ast.CallExpr:
+					reportBare(pass, decl, res, foreignCallee(pass, res))
+				case *ast.Ident:
+					if v := errorVar(pass, res); v != nil {
+						reportBare(pass, decl, res, assigned[v])
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+func reportBare(pass *analysis.Pass, decl *ast.FuncDecl, res ast.Expr, callee *types.Func) {
+	if callee == nil {
+		return
+	}
+	pass.Reportf(res.Pos(), "%s returns the error of %s.%s without Wrap or WithMessage",
+		decl.Name.Name, callee.Pkg().Name(), callee.Name())
+}
+
+// checkMessagef reports the WithMessagef calls given arguments while their
+// constant format has no verb.
+func checkMessagef(pass *analysis.Pass, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Name() != "WithMessagef" || fn.Pkg() == nil || !isErrorsPkg(fn.Pkg().Path()) {
+		return
+	}
+	if len(call.Args) < 3 || call.Ellipsis.IsValid() {
+		return
+	}
+	tv := pass.TypesInfo.Types[call.Args[1]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	if format := constant.StringVal(tv.Value); !hasVerb(format) {
+		pass.Reportf(call.Args[1].Pos(), "WithMessagef format %q has no verb but is given %d arguments",
+			format, len(call.Args)-2)
+	}
+}
+
+// hasVerb reports whether format has a verb other than %%.
+func hasVerb(format string) bool {
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] == '%' {
+			if format[i+1] != '%' {
+				return true
+			}
+			i++
+		}
+	}
+	return false
+}
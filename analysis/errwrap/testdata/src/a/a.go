@@ -0,0 +1,105 @@
+package a
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/mochi-c/errors"
+)
+
+type Logger struct{}
+
+func (Logger) Error(msg string, args ...any) {}
+
+func logAndReturn(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Printf("open %s: %v", name, err) // want `err is logged and returned: handle it or wrap it, not both`
+		return errors.WithMessage(err, "open")
+	}
+	if err := f.Close(); err != nil {
+		slog.Error("close", "err", err) // want `err is logged and returned: handle it or wrap it, not both`
+		return err
+	}
+	return nil
+}
+
+func logOrReturn(l Logger, err error) error {
+	if err == io.EOF {
+		l.Error("eof", "err", err)
+		return nil
+	}
+	switch {
+	case err != nil:
+		l.Error("failed", "err", err) // want `err is logged and returned: handle it or wrap it, not both`
+		return err
+	}
+	return err
+}
+
+func Open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err // want `Open returns the error of os.Open without Wrap or WithMessage`
+	}
+	return f, nil
+}
+
+func Remove(name string) error {
+	return os.Remove(name) // want `Remove returns the error of os.Remove without Wrap or WithMessage`
+}
+
+func Create(name string) (*os.File, error) {
+	return os.Create(name) // want `Create returns the error of os.Create without Wrap or WithMessage`
+}
+
+func Stat(name string) error {
+	_, err := os.Stat(name)
+	if err != nil {
+		err = errors.WithMessage(err, "stat")
+		return err
+	}
+	if err := local(); err != nil {
+		return err
+	}
+	return errors.Wrap(os.Remove(name))
+}
+
+func local() error {
+	return os.Remove("x")
+}
+
+type Reader struct{ r io.Reader }
+
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *Reader) Next(p []byte) error {
+	_, err := r.r.Read(p) // interface methods are foreign too
+	return err            // want `Next returns the error of io.Read without Wrap or WithMessage`
+}
+
+type reader struct{ r io.Reader }
+
+func (r *reader) Next(p []byte) error {
+	_, err := r.r.Read(p)
+	return err
+}
+
+func messagef(err error, n int) error {
+	_ = errors.WithMessagef(err, "read %d", n)
+	_ = errors.WithMessagef(err, "100%%")
+	_ = fmt.Sprintf("read", n)                 // vet's printf check covers fmt
+	return errors.WithMessagef(err, "read", n) // want `WithMessagef format "read" has no verb but is given 1 arguments`
+}
+
+type Config struct{ Name string }
+
+func (c *Config) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscan(string(text), &c.Name)
+	return err
+}
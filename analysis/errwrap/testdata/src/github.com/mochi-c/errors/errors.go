@@ -0,0 +1,10 @@
+// Package errors is a stub of github.com/mochi-c/errors.
+package errors
+
+func New(msg string) error { return nil }
+
+func Wrap(err error) error { return err }
+
+func WithMessage(err error, msg string) error { return err }
+
+func WithMessagef(err error, format string, args ...interface{}) error { return err }
@@ -7,7 +7,7 @@ import (
 )
 
 func BenchmarkGoErrorNew(b *testing.B) {
-	goError.New("error")
+	_ = goError.New("error")
 }
 
 func BenchmarkNew(b *testing.B) {
@@ -17,7 +17,7 @@ func BenchmarkNew(b *testing.B) {
 func BenchmarkErrorf(b *testing.B) {
 	err := goError.New("error")
 	b.ResetTimer()
-	fmt.Errorf("wrap it: %w", err)
+	_ = fmt.Errorf("wrap it: %w", err)
 }
 
 func BenchmarkWrap(b *testing.B) {
@@ -54,3 +54,28 @@ func BenchmarkMultiWrap(b *testing.B) {
 	b.ResetTimer()
 	Wrap(err)
 }
+
+func benchmarkCapture(b *testing.B, policy CapturePolicy) {
+	err := goError.New("error")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = recurse(64, func() error { return policy.Wrap(err) })
+	}
+}
+
+func BenchmarkCaptureDepth(b *testing.B) {
+	benchmarkCapture(b, CapturePolicy{Mode: CaptureDepth})
+}
+
+func BenchmarkCaptureOrigin(b *testing.B) {
+	benchmarkCapture(b, CapturePolicy{Mode: CaptureOrigin})
+}
+
+func BenchmarkCaptureFull(b *testing.B) {
+	benchmarkCapture(b, CapturePolicy{Mode: CaptureFull})
+}
+
+func BenchmarkCaptureDisabled(b *testing.B) {
+	benchmarkCapture(b, CapturePolicy{Mode: CaptureDisabled})
+}
@@ -0,0 +1,191 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// binaryMagic starts every error encoded by EncodeBinary, followed by the
+// version of the format.
+const (
+	binaryMagic   = "\xE7\x72"
+	binaryVersion = 1
+)
+
+// EncodeBinary is like EncodeJSON with a compact binary encoding,
+// decoded by DecodeBinary. ErrorInfo values are still encoded as JSON.
+// If err is nil, EncodeBinary returns nil.
+func EncodeBinary(err error) ([]byte, error) {
+	if err == nil {
+		return nil, nil
+	}
+	wire := newWireError(err)
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+	writeUvarint(&buf, len(wire.Stacks))
+	for _, frames := range wire.Stacks {
+		writeUvarint(&buf, len(frames))
+		for _, frame := range frames {
+			writeString(&buf, frame.Func)
+			writeString(&buf, frame.Path)
+			writeUvarint(&buf, frame.Num)
+		}
+	}
+	writeChain(&buf, wire.Chain)
+	return buf.Bytes(), nil
+}
+
+func writeChain(buf *bytes.Buffer, nodes []wireNode) {
+	writeUvarint(buf, len(nodes))
+	for _, node := range nodes {
+		writeString(buf, node.Type)
+		if node.Layer {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		writeString(buf, string(node.Info))
+		writeString(buf, node.Text)
+		writeString(buf, node.Message)
+		writeUvarint(buf, node.Stack)
+		writeUvarint(buf, len(node.Branches))
+		for _, branch := range node.Branches {
+			writeChain(buf, branch)
+		}
+	}
+}
+
+func writeUvarint(buf *bytes.Buffer, n int) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], uint64(n))])
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, len(s))
+	buf.WriteString(s)
+}
+
+// DecodeBinary rebuilds an error encoded by EncodeBinary, see DecodeJSON.
+// If data is empty, DecodeBinary returns nil.
+func DecodeBinary(data []byte) (error, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) || len(data) < len(binaryMagic)+1 {
+		return nil, fmt.Errorf("errors: decode: not an encoded error")
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return nil, fmt.Errorf("errors: decode: unsupported version %d", v)
+	}
+	r := &binaryReader{r: bytes.NewReader(data[len(binaryMagic)+1:])}
+	var wire wireError
+	wire.Stacks = make([][]RemoteFrame, r.length())
+	for i := range wire.Stacks {
+		frames := make([]RemoteFrame, r.length())
+		for j := range frames {
+			frames[j] = RemoteFrame{
+				Func: r.string(),
+				Path: r.string(),
+				Num:  r.uvarint(),
+			}
+		}
+		wire.Stacks[i] = frames
+	}
+	wire.Chain = r.chain()
+	if r.err == errWireTooDeep {
+		return nil, r.err
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("errors: decode: %w", r.err)
+	}
+	return decodeWireError(wire)
+}
+
+// binaryReader reads the encoding of EncodeBinary, keeping the first error.
+type binaryReader struct {
+	r     *bytes.Reader
+	err   error
+	depth int
+}
+
+func (r *binaryReader) chain() []wireNode {
+	if r.depth++; r.depth > maxWireDepth {
+		if r.err == nil {
+			r.err = errWireTooDeep
+		}
+		return nil
+	}
+	defer func() { r.depth-- }()
+	nodes := make([]wireNode, r.length())
+	for i := range nodes {
+		node := wireNode{Type: r.string()}
+		node.Layer = r.byte() == 1
+		if info := r.string(); info != "" {
+			node.Info = []byte(info)
+		}
+		node.Text = r.string()
+		node.Message = r.string()
+		node.Stack = r.uvarint()
+		if n := r.length(); n > 0 {
+			node.Branches = make([][]wireNode, n)
+			for j := range node.Branches {
+				node.Branches[j] = r.chain()
+			}
+		}
+		nodes[i] = node
+		if r.err != nil {
+			return nil
+		}
+	}
+	return nodes
+}
+
+func (r *binaryReader) uvarint() int {
+	if r.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		r.err = err
+		return 0
+	}
+	return int(n)
+}
+
+// length reads a length or a count, which is followed by as many bytes at
+// least, so corrupted data cannot make DecodeBinary allocate without bound.
+func (r *binaryReader) length() int {
+	n := r.uvarint()
+	if r.err == nil && n > r.r.Len() {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return n
+}
+
+func (r *binaryReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		r.err = err
+	}
+	return b
+}
+
+func (r *binaryReader) string() string {
+	n := r.length()
+	if r.err != nil {
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.err = err
+		return ""
+	}
+	return string(b)
+}
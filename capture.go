@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// defaultDepth is the number of frames captured by CaptureDepth
+// when no MaxDepth is given.
+const defaultDepth = 32
+
+// CaptureMode tells how much of the stack is recorded when an error
+// without stack is created or wrapped.
+type CaptureMode int
+
+const (
+	// CaptureDepth records at most MaxDepth frames, 32 when unset.
+	CaptureDepth CaptureMode = iota
+	// CaptureOrigin records only the frame the error comes from,
+	// enough for GetStackCause at a fraction of the cost.
+	CaptureOrigin
+	// CaptureFull records every frame, however deep the stack is.
+	CaptureFull
+	// CaptureDisabled records no stack: GetStack only finds the stacks
+	// already present in the chain, if any.
+	CaptureDisabled
+)
+
+// CapturePolicy tells how stacks are captured, see SetCapturePolicy to
+// change it for the whole package, or its methods to use it for one call.
+type CapturePolicy struct {
+	Mode     CaptureMode
+	MaxDepth int
+	// Skip is the number of extra frames to skip, so helpers built on top
+	// of this package record the stack of their own callers.
+	Skip int
+}
+
+var capturePolicy atomic.Pointer[CapturePolicy]
+
+// SetCapturePolicy sets the policy used by New, Errorf, Wrap, WithMessage,
+// WithMessagef, WithErrorInfo and Join. It is safe for concurrent use but
+// is meant to be called once at init.
+func SetCapturePolicy(policy CapturePolicy) {
+	capturePolicy.Store(&policy)
+}
+
+// GetCapturePolicy returns the policy set by SetCapturePolicy,
+// CaptureDepth with 32 frames by default.
+func GetCapturePolicy() CapturePolicy {
+	return loadCapturePolicy()
+}
+
+func loadCapturePolicy() CapturePolicy {
+	if policy := capturePolicy.Load(); policy != nil {
+		return *policy
+	}
+	return CapturePolicy{Mode: CaptureDepth, MaxDepth: defaultDepth}
+}
+
+// New is like the package New but captures the stack following p.
+func (p CapturePolicy) New(msg string) error {
+	cause := errors.New(msg)
+	return withErrorInfo(cause, emptyInfo{}, p)
+}
+
+// Errorf is like the package Errorf but captures the stack following p.
+func (p CapturePolicy) Errorf(format string, args ...interface{}) error {
+	cause := fmt.Errorf(format, args...)
+	return withErrorInfo(cause, emptyInfo{}, p)
+}
+
+// Wrap is like the package Wrap but captures the stack following p.
+func (p CapturePolicy) Wrap(err error) error {
+	if err != nil {
+		return withErrorInfo(err, emptyInfo{}, p)
+	} else {
+		return nil
+	}
+}
+
+// WithMessage is like the package WithMessage but captures the stack following p.
+func (p CapturePolicy) WithMessage(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, message(msg), p)
+}
+
+// WithMessagef is like the package WithMessagef but captures the stack following p.
+func (p CapturePolicy) WithMessagef(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	if len(args) > 0 {
+		return withErrorInfo(err, message(fmt.Sprintf(format, args...)), p)
+	} else {
+		return withErrorInfo(err, message(format), p)
+	}
+}
+
+// WithErrorInfoCapture is like WithErrorInfo but captures the stack following
+// policy; Go methods cannot have type parameters.
+func WithErrorInfoCapture[T ErrorInfo](err error, info T, policy CapturePolicy) error {
+	return withErrorInfo(err, info, policy)
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	goError "errors"
+	"testing"
+)
+
+func recurse(n int, fn func() error) error {
+	if n == 0 {
+		return fn()
+	}
+	return recurse(n-1, fn)
+}
+
+func TestCapturePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CapturePolicy
+		min    int
+		max    int
+	}{
+		{"default depth", CapturePolicy{}, 32, 32},
+		{"depth", CapturePolicy{Mode: CaptureDepth, MaxDepth: 5}, 5, 5},
+		{"origin", CapturePolicy{Mode: CaptureOrigin}, 1, 1},
+		{"full", CapturePolicy{Mode: CaptureFull}, 100, 1 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := recurse(100, func() error { return tt.policy.New("deep") })
+			stack, ok := GetStack(err)
+			if !ok {
+				t.Fatal("GetStack: no stack")
+			}
+			if n := len(stack.StackTrace()); n < tt.min || n > tt.max {
+				t.Errorf("got %d frames, want between %d and %d", n, tt.min, tt.max)
+			}
+			if frame, _ := GetStackCause(err); frame.FuncName() != "TestCapturePolicy.func1.1" {
+				t.Errorf("GetStackCause: got %s", frame.FuncName())
+			}
+		})
+	}
+}
+
+func TestCaptureDisabled(t *testing.T) {
+	disabled := CapturePolicy{Mode: CaptureDisabled}
+
+	err := disabled.WithMessage(goError.New("whoops"), "msg")
+	if _, ok := GetStack(err); ok {
+		t.Errorf("GetStack: found a stack while disabled")
+	}
+	if _, ok := GetStackCause(err); ok {
+		t.Errorf("GetStackCause: found a stack while disabled")
+	}
+	if err.Error() != "msg: whoops" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+
+	err = WithMessage(err, "enabled")
+	if frame, ok := GetStackCause(err); !ok || frame.FuncName() != "TestCaptureDisabled" {
+		t.Errorf("GetStackCause after enabling: got %v %v", frame, ok)
+	}
+
+	withStack := New("whoops")
+	want, _ := GetStack(withStack)
+	if got, ok := GetStack(disabled.Wrap(withStack)); !ok || got != want {
+		t.Errorf("GetStack: existing stack not kept while disabled")
+	}
+}
+
+func TestSetCapturePolicy(t *testing.T) {
+	defer SetCapturePolicy(GetCapturePolicy())
+
+	SetCapturePolicy(CapturePolicy{Mode: CaptureOrigin})
+	stack, _ := GetStack(New("whoops"))
+	if n := len(stack.StackTrace()); n != 1 {
+		t.Errorf("got %d frames, want 1", n)
+	}
+
+	SetCapturePolicy(CapturePolicy{Mode: CaptureDisabled})
+	if _, ok := GetStack(WithErrorInfo(goError.New("whoops"), CodeInfo{Code: 100})); ok {
+		t.Errorf("GetStack: found a stack while disabled")
+	}
+	if _, ok := GetStack(Join(goError.New("whoops"))); ok {
+		t.Errorf("GetStack: found a join stack while disabled")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/mochi-c/errors/cmd/errmigrate
+
+go 1.21
+
+require golang.org/x/tools v0.21.0
@@ -0,0 +1,109 @@
+// Command errmigrate rewrites Go source files using github.com/pkg/errors,
+// or wrapping errors with fmt.Errorf, to use github.com/mochi-c/errors.
+//
+// Usage:
+//
+//	errmigrate [-d] [path ...]
+//
+// Every .go file of the given files and directories, recursively, is
+// rewritten in place; the current directory is used when no path is given.
+// Directories named vendor or testdata and those starting with . or _ are
+// skipped. With -d, the changes are printed as unified diffs and no file is
+// written.
+//
+// The calls of pkg/errors are renamed to their counterparts: Wrap becomes
+// WithMessage, Wrapf becomes WithMessagef and WithStack becomes Wrap, while
+// Is, As and Unwrap move to the standard errors package. Files using other
+// identifiers of pkg/errors, such as StackTrace, import the pkgcompat
+// package instead, which provides its whole API.
+//
+// Calls of fmt.Errorf whose format ends with ": %w" wrapping their last
+// argument become calls of WithMessage or WithMessagef, and fmt.Errorf("%w",
+// err) becomes Wrap(err). Unlike fmt.Errorf, these return nil when err is
+// nil, so only the calls in the body of an if checking err != nil are
+// rewritten; the others are reported on the standard error and left
+// unchanged.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var dryRun = flag.Bool("d", false, "print diffs instead of rewriting files")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errmigrate [-d] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	exitCode := 0
+	for _, path := range paths {
+		if err := walkPath(path, os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+// walkPath migrates path, or every Go file below it if it is a directory,
+// writing the calls left unchanged to reports.
+func walkPath(root string, out, reports io.Writer) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path != root && !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return processFile(path, d, out, reports)
+	})
+}
+
+// processFile migrates a file, writing it back or its diff to out.
+func processFile(filename string, d fs.DirEntry, out, reports io.Writer) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	res, unchanged, err := migrate(filename, src)
+	if err != nil {
+		return err
+	}
+	for _, report := range unchanged {
+		fmt.Fprintln(reports, report)
+	}
+	if bytes.Equal(src, res) {
+		return nil
+	}
+	if *dryRun {
+		_, err = out.Write(diff(filename, src, res))
+		return err
+	}
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, res, info.Mode().Perm())
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	modulePath    = "github.com/mochi-c/errors"
+	compatPath    = modulePath + "/pkgcompat"
+	pkgErrorsPath = "github.com/pkg/errors"
+)
+
+// pkgRenames maps the functions of pkg/errors to the functions of this
+// package with the same signature.
+var pkgRenames = map[string]string{
+	"New":          "New",
+	"Errorf":       "Errorf",
+	"WithMessage":  "WithMessage",
+	"WithMessagef": "WithMessagef",
+	"Cause":        "Cause",
+	"Wrap":         "WithMessage",
+	"Wrapf":        "WithMessagef",
+	"WithStack":    "Wrap",
+}
+
+// stdFuncs are the functions of pkg/errors forwarding to the standard errors.
+var stdFuncs = map[string]bool{"Is": true, "As": true, "Unwrap": true}
+
+// migrate returns src rewritten to use this package, or src itself when
+// there is nothing to rewrite, and the fmt.Errorf calls left unchanged
+// because their error may be nil, as file:line:col: message.
+func migrate(filename string, src []byte) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	changed, unguarded := migrateFile(fset, file)
+	var reports []string
+	for _, call := range unguarded {
+		reports = append(reports, fmt.Sprintf("%s: fmt.Errorf left unchanged: %s is not checked against nil first",
+			fset.Position(call.Pos()), types.ExprString(call.Args[len(call.Args)-1])))
+	}
+	if !changed {
+		return src, reports, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), reports, nil
+}
+
+// migrator rewrites a file, the nodes are changed in place so comments stay
+// where they were.
+type migrator struct {
+	fset *token.FileSet
+	file *ast.File
+	// local names of the imports, "" when not imported
+	pkgName, coreName, stdName, fmtName string
+	changed                             bool
+}
+
+// migrateFile rewrites file and reports whether it changed, and returns the
+// fmt.Errorf calls left unchanged because they are not guarded.
+func migrateFile(fset *token.FileSet, file *ast.File) (bool, []*ast.CallExpr) {
+	m := &migrator{
+		fset:     fset,
+		file:     file,
+		pkgName:  importName(file, pkgErrorsPath),
+		coreName: importName(file, modulePath),
+		stdName:  importName(file, "errors"),
+		fmtName:  importName(file, "fmt"),
+	}
+	calls, unguarded := m.errorfCalls()
+	wrapName := "Wrap"
+	if m.pkgName != "" {
+		if m.usesUnsupported() {
+			// pkgcompat has the same API as pkg/errors
+			astutil.RewriteImport(fset, file, pkgErrorsPath, compatPath)
+			m.coreName, m.changed = m.pkgName, true
+			wrapName = "WithStack"
+		} else {
+			m.migratePkgErrors()
+		}
+	}
+	if len(calls) > 0 && m.coreName == "" {
+		m.addCoreImport()
+	}
+	if m.coreName != "" {
+		for _, call := range calls {
+			m.rewriteErrorf(call, wrapName)
+		}
+	}
+	if m.changed && m.fmtName != "" && !astutil.UsesImport(file, "fmt") {
+		astutil.DeleteImport(fset, file, "fmt")
+	}
+	return m.changed, unguarded
+}
+
+// importName returns the name path is imported as by file, or "" when it is
+// not imported or imported as _ or .
+func importName(file *ast.File, importPath string) string {
+	for _, spec := range file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != importPath {
+			continue
+		}
+		if spec.Name == nil {
+			return path.Base(importPath)
+		}
+		if spec.Name.Name == "_" || spec.Name.Name == "." {
+			return ""
+		}
+		return spec.Name.Name
+	}
+	return ""
+}
+
+// nameTaken reports whether an import of file is named name.
+func nameTaken(file *ast.File, name string) bool {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name || spec.Name == nil && path.Base(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectors returns the selectors of the package imported as name.
+// Package names are left unresolved by the parser, unlike local variables.
+func (m *migrator) selectors(name string) []*ast.SelectorExpr {
+	var res []*ast.SelectorExpr
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+				res = append(res, sel)
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// usesUnsupported reports whether the file uses pkg/errors identifiers
+// that have no counterpart, such as StackTrace.
+func (m *migrator) usesUnsupported() bool {
+	for _, sel := range m.selectors(m.pkgName) {
+		if _, ok := pkgRenames[sel.Sel.Name]; !ok && !stdFuncs[sel.Sel.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// migratePkgErrors renames the pkg/errors selectors and replaces its import.
+func (m *migrator) migratePkgErrors() {
+	sels := m.selectors(m.pkgName)
+	for _, sel := range sels {
+		if stdFuncs[sel.Sel.Name] {
+			sel.X.(*ast.Ident).Name = m.std()
+		}
+	}
+	for _, sel := range sels {
+		if name, ok := pkgRenames[sel.Sel.Name]; ok {
+			sel.Sel.Name = name
+			if m.coreName != "" {
+				sel.X.(*ast.Ident).Name = m.coreName
+			}
+		}
+	}
+	if m.coreName != "" {
+		astutil.DeleteImport(m.fset, m.file, pkgErrorsPath)
+	} else {
+		astutil.RewriteImport(m.fset, m.file, pkgErrorsPath, modulePath)
+		m.coreName = m.pkgName
+	}
+	m.changed = true
+}
+
+// std returns the name of the standard errors package, importing it first
+// if needed. pkg/errors, imported as errors, is about to be replaced by this
+// package then.
+func (m *migrator) std() string {
+	if m.stdName == "" {
+		m.stdName = "stderrors"
+		astutil.AddNamedImport(m.fset, m.file, m.stdName, "errors")
+	}
+	return m.stdName
+}
+
+// addCoreImport imports this package as errors, renaming the standard errors
+// package to stderrors if it was imported as errors.
+func (m *migrator) addCoreImport() {
+	if m.stdName == "errors" {
+		for _, sel := range m.selectors("errors") {
+			sel.X.(*ast.Ident).Name = "stderrors"
+		}
+		for _, spec := range m.file.Imports {
+			if spec.Path.Value == `"errors"` {
+				spec.Name = &ast.Ident{NamePos: spec.Path.Pos(), Name: "stderrors"}
+			}
+		}
+		m.stdName = "stderrors"
+	} else if nameTaken(m.file, "errors") {
+		return
+	}
+	astutil.AddImport(m.fset, m.file, modulePath)
+	m.coreName = "errors"
+}
+
+// errorfCalls returns the fmt.Errorf calls rewriteErrorf can rewrite, and
+// those it could but leaves unchanged because they are not guarded: the
+// functions of this package return nil for a nil error, unlike fmt.Errorf,
+// so only the calls in the body of an if checking their error against nil
+// are rewritten.
+func (m *migrator) errorfCalls() (calls, unguarded []*ast.CallExpr) {
+	if m.fmtName == "" {
+		return nil, nil
+	}
+	// the nodes enclosing the one visited, outermost first
+	var stack []ast.Node
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			if _, _, ok := m.errorfRewrite(call); ok {
+				if guarded(stack, call.Args[len(call.Args)-1]) {
+					calls = append(calls, call)
+				} else {
+					unguarded = append(unguarded, call)
+				}
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return calls, unguarded
+}
+
+// guarded reports whether err, found below the nodes of stack, is a
+// variable checked against nil by an enclosing if of the same function,
+// such as in if err != nil { ... }.
+func guarded(stack []ast.Node, err ast.Expr) bool {
+	id, ok := err.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		case *ast.IfStmt:
+			if i+1 < len(stack) && stack[i+1] == n.Body && checksNotNil(n.Cond, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checksNotNil reports whether cond being true implies id != nil.
+func checksNotNil(cond ast.Expr, id *ast.Ident) bool {
+	switch cond := cond.(type) {
+	case *ast.ParenExpr:
+		return checksNotNil(cond.X, id)
+	case *ast.BinaryExpr:
+		switch cond.Op {
+		case token.LAND:
+			return checksNotNil(cond.X, id) || checksNotNil(cond.Y, id)
+		case token.NEQ:
+			return isNil(cond.Y) && sameVar(cond.X, id) || isNil(cond.X) && sameVar(cond.Y, id)
+		}
+	}
+	return false
+}
+
+func isNil(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil" && id.Obj == nil
+}
+
+// sameVar reports whether e is the variable id, as resolved by the parser.
+func sameVar(e ast.Expr, id *ast.Ident) bool {
+	x, ok := e.(*ast.Ident)
+	return ok && x.Name == id.Name && x.Obj == id.Obj
+}
+
+// errorfRewrite returns the format of call without its trailing ": %w" and
+// the number of arguments it formats, if call is a fmt.Errorf call wrapping
+// its last argument with such a format.
+func (m *migrator) errorfRewrite(call *ast.CallExpr) (lit *ast.BasicLit, verbs int, ok bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Errorf" || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+		return nil, 0, false
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || id.Name != m.fmtName || id.Obj != nil {
+		return nil, 0, false
+	}
+	lit, ok = call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil, 0, false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil, 0, false
+	}
+	if format == "%w" {
+		return lit, 0, len(call.Args) == 2
+	}
+	// the literal itself must end with ": %w" to be cut without unquoting
+	if !strings.HasSuffix(format, ": %w") || !strings.HasSuffix(lit.Value[:len(lit.Value)-1], ": %w") {
+		return nil, 0, false
+	}
+	verbs = countVerbs(strings.TrimSuffix(format, ": %w"))
+	return lit, verbs, verbs >= 0 && len(call.Args) == verbs+2
+}
+
+// rewriteErrorf rewrites a call returned by errorfCalls.
+func (m *migrator) rewriteErrorf(call *ast.CallExpr, wrapName string) {
+	lit, verbs, _ := m.errorfRewrite(call)
+	sel := call.Fun.(*ast.SelectorExpr)
+	sel.X.(*ast.Ident).Name = m.coreName
+	cause := call.Args[len(call.Args)-1]
+	format, _ := strconv.Unquote(lit.Value)
+	switch {
+	case format == "%w":
+		sel.Sel.Name = wrapName
+		call.Args = []ast.Expr{cause}
+	case verbs == 0:
+		sel.Sel.Name = "WithMessage"
+		msg := strings.TrimSuffix(format, ": %w")
+		if strings.Contains(msg, "%") {
+			lit.Value = strconv.Quote(strings.ReplaceAll(msg, "%%", "%"))
+		} else {
+			lit.Value = lit.Value[:len(lit.Value)-len(`: %w"`)] + lit.Value[len(lit.Value)-1:]
+		}
+		call.Args = []ast.Expr{cause, lit}
+	default:
+		sel.Sel.Name = "WithMessagef"
+		lit.Value = lit.Value[:len(lit.Value)-len(`: %w"`)] + lit.Value[len(lit.Value)-1:]
+		call.Args = append([]ast.Expr{cause, lit}, call.Args[1:len(call.Args)-1]...)
+	}
+	m.changed = true
+}
+
+// countVerbs returns the number of arguments format consumes, or -1 when it
+// uses %w, explicit argument indexes or * widths, which are not rewritten.
+func countVerbs(format string) int {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) || strings.IndexByte("*[w", format[i]) >= 0 {
+			return -1
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestMigrate(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := migrate(input, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(input, ".input") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+			// migrating twice changes nothing
+			again, _, err := migrate(golden, got)
+			if err != nil || !bytes.Equal(again, got) {
+				t.Errorf("second run: got:\n%s\n%v", again, err)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/errorf.input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "errorf.go")
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	*dryRun = true
+	defer func() { *dryRun = false }()
+	var out, reports bytes.Buffer
+	if err := walkPath(dir, &out, &reports); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ReplaceAll(reports.String(), file, "errorf.go"); got != "errorf.go:31:9: fmt.Errorf left unchanged: err is not checked against nil first\n" {
+		t.Errorf("reports: got %q", got)
+	}
+	got := strings.ReplaceAll(out.String(), file, "errorf.go")
+	if *update {
+		if err := os.WriteFile("testdata/errorf.diff", []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/errorf.diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if res, _ := os.ReadFile(file); !bytes.Equal(res, src) {
+		t.Errorf("dry run rewrote the file:\n%s", res)
+	}
+
+	*dryRun = false
+	if err := walkPath(dir, &out, &reports); err != nil {
+		t.Fatal(err)
+	}
+	want, _ = os.ReadFile("testdata/errorf.golden")
+	if res, _ := os.ReadFile(file); !bytes.Equal(res, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", res, want)
+	}
+}
+
+func TestMigrateUnguarded(t *testing.T) {
+	src := `package p
+
+import "fmt"
+
+func f(err error, e struct{ err error }) (error, func() error) {
+	if err == nil {
+		return fmt.Errorf("nil: %w", err), nil
+	}
+	if err != nil {
+		return fmt.Errorf("guarded: %w", err), func() error { return fmt.Errorf("later: %w", err) }
+	}
+	return fmt.Errorf("field: %w", e.err), nil
+}
+`
+	got, reports, err := migrate("p.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `errors.WithMessage(err, "guarded")`) || strings.Count(string(got), "fmt.Errorf") != 3 {
+		t.Errorf("got:\n%s", got)
+	}
+	want := []string{
+		"p.go:7:10: fmt.Errorf left unchanged: err is not checked against nil first",
+		"p.go:10:64: fmt.Errorf left unchanged: err is not checked against nil first",
+		"p.go:12:9: fmt.Errorf left unchanged: e.err is not checked against nil first",
+	}
+	if strings.Join(reports, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reports: got\n%s\nwant\n%s", strings.Join(reports, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mochi-c/errors"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   options
+		golden string
+	}{
+		{"default", options{fold: true, trim: true}, "testdata/logs.golden"},
+		{"raw", options{}, "testdata/logs.raw.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGrouper(tt.opts)
+			if err := scanFile("testdata/logs.txt", g.add); err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			g.print(&got)
+			if *update {
+				if err := os.WriteFile(tt.golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestErrorsFormats(t *testing.T) {
+	var logs bytes.Buffer
+	for i := 0; i < 4; i++ {
+		err := errors.WithMessage(errors.New("whoops"), "msg")
+		if i%2 == 0 {
+			fmt.Fprintf(&logs, "%+v\n", err)
+		} else {
+			data, _ := errors.ToJSON(err)
+			fmt.Fprintf(&logs, "%s\n", data)
+		}
+	}
+
+	g := newGrouper(options{fold: true, trim: true})
+	if err := scan(&logs, g.add); err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	g.print(&got)
+	lines := strings.Split(got.String(), "\n")
+	if len(lines) < 3 || lines[0] != "4 identical traces" ||
+		lines[1] != "github.com/mochi-c/errors/cmd/errstack.TestErrorsFormats" || !strings.HasSuffix(lines[2], "errstack_test.go:52") {
+		t.Errorf("got:\n%s", got.String())
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// options tells how traces are normalized before being grouped.
+type options struct {
+	fold bool
+	trim bool
+}
+
+// group is a set of identical traces.
+type group struct {
+	trace trace
+	count int
+	// first is the rank of the group, in order of appearance
+	first int
+}
+
+// grouper groups identical traces once normalized.
+type grouper struct {
+	opts   options
+	groups map[string]*group
+}
+
+func newGrouper(opts options) *grouper {
+	return &grouper{opts: opts, groups: make(map[string]*group)}
+}
+
+func (g *grouper) add(t trace) {
+	t = g.opts.normalize(t)
+	var key strings.Builder
+	for _, f := range t {
+		fmt.Fprintf(&key, "%s\x00%s\x00%d\n", f.Func, f.File, f.Line)
+	}
+	if gr, ok := g.groups[key.String()]; ok {
+		gr.count++
+		return
+	}
+	g.groups[key.String()] = &group{trace: t, count: 1, first: len(g.groups)}
+}
+
+// print writes the groups, the most frequent first.
+func (g *grouper) print(w io.Writer) {
+	groups := make([]*group, 0, len(g.groups))
+	for _, gr := range g.groups {
+		groups = append(groups, gr)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return groups[i].first < groups[j].first
+	})
+	for i, gr := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if gr.count == 1 {
+			fmt.Fprintln(w, "1 trace")
+		} else {
+			fmt.Fprintf(w, "%d identical traces\n", gr.count)
+		}
+		for _, f := range gr.trace {
+			if f.File == "" {
+				fmt.Fprintln(w, f.Func)
+				continue
+			}
+			fmt.Fprintf(w, "%s\n\t%s:%d\n", f.Func, f.File, f.Line)
+		}
+	}
+}
+
+// normalize returns t with the names of generic functions demangled, the
+// file names trimmed and the runtime and testing frames folded, as asked.
+func (o options) normalize(t trace) trace {
+	res := make(trace, 0, len(t))
+	folded := 0
+	for _, f := range t {
+		f.Func = demangle(f.Func)
+		if o.trim {
+			f.File = trimPath(f.Func, f.File)
+		}
+		if o.fold && isFolded(f.Func) {
+			folded++
+			continue
+		}
+		res = appendFolded(res, folded)
+		folded = 0
+		res = append(res, f)
+	}
+	return appendFolded(res, folded)
+}
+
+// appendFolded appends to t a line standing for n folded frames.
+func appendFolded(t trace, n int) trace {
+	switch n {
+	case 0:
+		return t
+	case 1:
+		return append(t, frame{Func: "... 1 runtime or testing frame"})
+	default:
+		return append(t, frame{Func: fmt.Sprintf("... %d runtime or testing frames", n)})
+	}
+}
+
+// demangle replaces the type arguments of generic functions, such as
+// [go.shape.int] or [...], by [...].
+func demangle(fn string) string {
+	if !strings.Contains(fn, "[") {
+		return fn
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range fn {
+		switch {
+		case r == '[':
+			if depth == 0 {
+				b.WriteString("[...]")
+			}
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// funcPackage returns the import path of the package of fn.
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return fn
+	}
+	return fn[:slash+1+dot]
+}
+
+// isFolded reports whether fn is a function of the runtime or testing.
+func isFolded(fn string) bool {
+	pkg := funcPackage(fn)
+	return pkg == "runtime" || pkg == "testing" || strings.HasPrefix(pkg, "runtime/")
+}
+
+// trimPath trims the module cache prefix from file, or the GOROOT prefix
+// when fn belongs to the standard library.
+func trimPath(fn, file string) string {
+	const modCache = "/pkg/mod/"
+	if i := strings.LastIndex(file, modCache); i >= 0 {
+		return file[i+len(modCache):]
+	}
+	pkg := funcPackage(fn)
+	if first, _, _ := strings.Cut(pkg, "/"); !strings.Contains(first, ".") {
+		if i := strings.LastIndex(file, "/src/"+pkg+"/"); i >= 0 {
+			return file[i+len("/src/"):]
+		}
+	}
+	return file
+}
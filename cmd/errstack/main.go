@@ -0,0 +1,86 @@
+// Command errstack collapses the stack traces found in logs.
+//
+// Usage:
+//
+//	errstack [-fold=false] [-trim=false] [file ...]
+//
+// It reads the files given, or the standard input, and finds the stacks
+// printed with %+v by github.com/mochi-c/errors, which are also those of Go
+// panics, and the stacks of its JSON forms, one document per line: ToJSON,
+// EncodeJSON and the errors logged through log/slog.
+//
+// Identical traces are grouped and printed with their count, the most
+// frequent first. The type arguments of generic functions are shown as
+// [...], the GOROOT and module cache prefixes are trimmed from file names,
+// and consecutive runtime and testing frames are folded into one line.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	fold = flag.Bool("fold", true, "fold consecutive runtime and testing frames")
+	trim = flag.Bool("trim", true, "trim GOROOT and module cache prefixes from file names")
+)
+
+// maxLine is the length of the longest line read, JSON logs can be long.
+const maxLine = 64 << 20
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errstack [-fold=false] [-trim=false] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	g := newGrouper(options{fold: *fold, trim: *trim})
+	exitCode := 0
+	if flag.NArg() == 0 {
+		if err := scan(os.Stdin, g.add); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	for _, name := range flag.Args() {
+		if err := scanFile(name, g.add); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	w := bufio.NewWriter(os.Stdout)
+	g.print(w)
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+func scanFile(name string, emit func(trace)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := scan(f, emit); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// scan calls emit with every trace of r.
+func scan(r io.Reader, emit func(trace)) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64<<10), maxLine)
+	p := &parser{emit: emit}
+	for s.Scan() {
+		p.line(s.Text())
+	}
+	p.flush()
+	return s.Err()
+}
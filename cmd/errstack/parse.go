@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// frame is a frame of a trace, as logged.
+type frame struct {
+	Func string
+	File string
+	Line int
+}
+
+// trace is a stack, innermost frame first.
+type trace []frame
+
+// parser finds the traces of the lines of a log.
+//
+// In text, a frame is a line holding the function, followed by a line
+// holding its file and line, indented by a tab, the way %+v and Go panics
+// print them. A trace ends at the first line that is not a frame.
+type parser struct {
+	emit func(trace)
+	cur  trace
+	// fn is the previous line, when it may be the function of a frame
+	fn    string
+	hasFn bool
+}
+
+func (p *parser) line(line string) {
+	if p.hasFn {
+		p.hasFn = false
+		if file, n, ok := parseFileLine(line); ok {
+			p.cur = append(p.cur, frame{Func: p.fn, File: file, Line: n})
+			return
+		}
+		p.flush()
+	}
+	switch {
+	case strings.HasPrefix(line, "{"):
+		p.flush()
+		p.json(line)
+	case line != "" && line[0] != ' ' && line[0] != '\t':
+		p.fn, p.hasFn = funcName(line), true
+	default:
+		p.flush()
+	}
+}
+
+// flush emits the current trace, if any.
+func (p *parser) flush() {
+	if len(p.cur) > 0 {
+		p.emit(p.cur)
+		p.cur = nil
+	}
+}
+
+// parseFileLine parses a line holding a file and a line number, such as
+// "\t/src/main.go:12", followed by the pc offset in panics.
+func parseFileLine(line string) (file string, n int, ok bool) {
+	if !strings.HasPrefix(line, "\t") {
+		return "", 0, false
+	}
+	line = line[1:]
+	if i := strings.LastIndex(line, " +0x"); i >= 0 {
+		line = line[:i]
+	}
+	return splitFileLine(line)
+}
+
+// splitFileLine splits "file:line".
+func splitFileLine(s string) (file string, n int, ok bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return s[:i], n, true
+}
+
+// funcName returns the function of a frame line, without the arguments
+// and the goroutine printed by panics.
+func funcName(line string) string {
+	if name, ok := strings.CutPrefix(line, "created by "); ok {
+		line = name
+		if i := strings.Index(line, " in goroutine "); i >= 0 {
+			line = line[:i]
+		}
+	}
+	if strings.HasSuffix(line, ")") {
+		if i := strings.LastIndexByte(line, '('); i > 0 && line[i-1] != '.' {
+			line = line[:i]
+		}
+	}
+	return line
+}
+
+// json finds the traces of a JSON document: the stack of ToJSON and of
+// the errors logged with log/slog, and the stacks of EncodeJSON.
+func (p *parser) json(line string) {
+	var doc any
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return
+	}
+	p.jsonValue(doc)
+}
+
+func (p *parser) jsonValue(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		// in order, so traces are found in the same order every time
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := v[key]
+			switch key {
+			case "stack":
+				if t := jsonTrace(value); len(t) > 0 {
+					p.emit(t)
+					continue
+				}
+			case "stacks":
+				if stacks, ok := value.([]any); ok {
+					for _, stack := range stacks {
+						if t := jsonTrace(stack); len(t) > 0 {
+							p.emit(t)
+						}
+					}
+					continue
+				}
+			}
+			p.jsonValue(value)
+		}
+	case []any:
+		for _, value := range v {
+			p.jsonValue(value)
+		}
+	}
+}
+
+// jsonTrace returns the trace of a JSON stack, either frames such as
+// {"func":…,"file":…,"line":…} or strings such as "func file:line".
+func jsonTrace(v any) trace {
+	frames, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	t := make(trace, 0, len(frames))
+	for _, f := range frames {
+		switch f := f.(type) {
+		case map[string]any:
+			fn, _ := f["func"].(string)
+			file, _ := f["file"].(string)
+			line, _ := f["line"].(float64)
+			if fn == "" && file == "" {
+				return nil
+			}
+			t = append(t, frame{Func: fn, File: file, Line: int(line)})
+		case string:
+			fn, loc, _ := strings.Cut(f, " ")
+			file, line, ok := splitFileLine(loc)
+			if !ok {
+				return nil
+			}
+			t = append(t, frame{Func: fn, File: file, Line: line})
+		default:
+			return nil
+		}
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Code identifies a kind of error across services, see RegisterCode.
+// Code is an ErrorInfo: it can be attached with WithCode and read back with
+// GetCode, GetOriginalCode or IsCode.
+type Code int
+
+// CodeDef describes a registered Code.
+type CodeDef struct {
+	Code        Code
+	Name        string
+	Description string
+	// HTTPStatus is the HTTP status code errors with this Code map to.
+	HTTPStatus int
+	// GRPCCode is the google.golang.org/grpc/codes.Code errors with
+	// this Code map to.
+	GRPCCode uint32
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = make(map[Code]CodeDef)
+)
+
+// RegisterCode registers code with its name, description and the HTTP status
+// and gRPC code it maps to, and returns it, so catalogs can be declared as
+//
+//	var CodeNotFound = errors.RegisterCode(1001, "NOT_FOUND", "resource not found", 404, 5)
+//
+// RegisterCode panics if code was already registered, so conflicting
+// catalogs are detected at init.
+func RegisterCode(code Code, name, description string, httpStatus int, grpcCode uint32) Code {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	if def, ok := codes[code]; ok {
+		panic(fmt.Sprintf("errors: code %d registered twice, as %s and %s", code, def.Name, name))
+	}
+	codes[code] = CodeDef{
+		Code:        code,
+		Name:        name,
+		Description: description,
+		HTTPStatus:  httpStatus,
+		GRPCCode:    grpcCode,
+	}
+	return code
+}
+
+// LookupCode returns the definition code was registered with.
+func LookupCode(code Code) (CodeDef, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	def, ok := codes[code]
+	return def, ok
+}
+
+// String returns the registered name of c followed by its value,
+// or only its value when c is not registered.
+func (c Code) String() string {
+	if def, ok := LookupCode(c); ok {
+		return fmt.Sprintf("%s(%d)", def.Name, int(c))
+	}
+	return fmt.Sprintf("%d", int(c))
+}
+
+// WhenError leaves the message of cause unchanged.
+func (c Code) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "code " + c.String()
+	}
+}
+
+// WithCode annotates err with code.
+// If err is nil, WithCode returns nil.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, code, loadCapturePolicy())
+}
+
+// GetCode returns the outermost Code attached to err.
+func GetCode(err error) (Code, bool) {
+	return GetErrorInfo[Code](err)
+}
+
+// GetOriginalCode returns the innermost Code attached to err.
+func GetOriginalCode(err error) (Code, bool) {
+	return GetOriginalErrorInfo[Code](err)
+}
+
+// IsCode reports whether code is attached to any layer of err.
+func IsCode(err error, code Code) bool {
+	for _, c := range GetAllErrorInfo[Code](err) {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
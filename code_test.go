@@ -0,0 +1,63 @@
+package errors
+
+import (
+	goError "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	testCodeNotFound = RegisterCode(-1001, "NOT_FOUND", "resource not found", 404, 5)
+	testCodeInternal = RegisterCode(-1002, "INTERNAL", "internal error", 500, 13)
+)
+
+func TestRegisterCode(t *testing.T) {
+	def, ok := LookupCode(testCodeNotFound)
+	if !ok || def.Name != "NOT_FOUND" || def.HTTPStatus != 404 || def.GRPCCode != 5 {
+		t.Errorf("LookupCode: got %+v %v", def, ok)
+	}
+	if _, ok := LookupCode(-1); ok {
+		t.Errorf("LookupCode: found an unregistered code")
+	}
+	if got := testCodeNotFound.String(); got != "NOT_FOUND(-1001)" {
+		t.Errorf("String(): got %q", got)
+	}
+	if got := Code(-1).String(); got != "-1" {
+		t.Errorf("String() unregistered: got %q", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "registered twice") {
+			t.Errorf("RegisterCode twice: got %v, want a panic", r)
+		}
+	}()
+	RegisterCode(testCodeNotFound, "OTHER", "", 400, 3)
+}
+
+func TestWithCode(t *testing.T) {
+	if got := WithCode(nil, testCodeNotFound); got != nil {
+		t.Errorf("WithCode(nil): got %#v, expected nil", got)
+	}
+
+	err := WithCode(goError.New("whoops"), testCodeNotFound)
+	err = WithMessage(err, "msg")
+	err = fmt.Errorf("foreign: %w", err)
+	err = WithCode(err, testCodeInternal)
+
+	if err.Error() != "foreign: msg: whoops" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	if code, ok := GetCode(err); !ok || code != testCodeInternal {
+		t.Errorf("GetCode: got %v %v", code, ok)
+	}
+	if code, ok := GetOriginalCode(err); !ok || code != testCodeNotFound {
+		t.Errorf("GetOriginalCode: got %v %v", code, ok)
+	}
+	if !IsCode(err, testCodeNotFound) || !IsCode(err, testCodeInternal) || IsCode(err, -1) {
+		t.Errorf("IsCode: wrong result")
+	}
+	if _, ok := GetCode(goError.New("whoops")); ok {
+		t.Errorf("GetCode: found a code in a foreign error")
+	}
+}
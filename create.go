@@ -7,10 +7,10 @@ import (
 
 func New(msg string) error {
 	cause := errors.New(msg)
-	return withErrorInfo(cause, emptyInfo{})
+	return withErrorInfo(cause, emptyInfo{}, loadCapturePolicy())
 }
 
 func Errorf(format string, args ...interface{}) error {
 	cause := fmt.Errorf(format, args...)
-	return withErrorInfo(cause, emptyInfo{})
+	return withErrorInfo(cause, emptyInfo{}, loadCapturePolicy())
 }
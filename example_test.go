@@ -107,12 +107,11 @@ func ExampleGetStackCause() {
 	frame, ok := GetStackCause(err)
 	fmt.Println(frame.FuncName(), frame.Line(), ok)
 
-	// Output:	ExampleGetStackCause 106 true
-
 	/*
 		fmt.Println(frame.FuncName(), frame.File(), frame.Line(), ok)
 		like this
 		ExampleGetStackCause /Users/bytedance/workspace/tiktok/errors/example_test.go 106 true
 	*/
 
+	// Output:	ExampleGetStackCause 106 true
 }
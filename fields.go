@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// badKey is the key used for values without a string key, as log/slog does.
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to an error by WithField or WithFields.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Fields is the ErrorInfo attached by WithField and WithFields.
+// It can be read back with GetFields or GetErrorInfo[Fields].
+type Fields []Field
+
+// WhenError leaves the message of cause unchanged. On its own it lists its
+// fields as key=value, which is how they are shown by %+v.
+func (fields Fields) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		pairs := make([]string, len(fields))
+		for i, field := range fields {
+			pairs[i] = fmt.Sprintf("%s=%v", field.Key, field.Value)
+		}
+		return strings.Join(pairs, " ")
+	}
+}
+
+// MarshalJSON encodes fields as a JSON object keeping their order.
+func (fields Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes fields from a JSON object keeping their order.
+func (fields *Fields) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok == nil {
+		*fields = nil
+		return nil
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("errors: cannot unmarshal %v into Fields", tok)
+	}
+	res := make(Fields, 0)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		res = append(res, Field{Key: tok.(string), Value: value})
+	}
+	*fields = res
+	return nil
+}
+
+// WithField annotates err with a key/value pair.
+// If err is nil, WithField returns nil.
+func WithField(err error, key string, value any) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, Fields{{Key: key, Value: value}}, loadCapturePolicy())
+}
+
+// WithFields annotates err with alternating keys and values, like the
+// arguments of slog.Logger.Info. A value without a string key before it
+// gets the key "!BADKEY".
+// If err is nil, WithFields returns nil.
+func WithFields(err error, kv ...any) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, newFields(kv), loadCapturePolicy())
+}
+
+func newFields(kv []any) Fields {
+	fields := make(Fields, 0, (len(kv)+1)/2)
+	for len(kv) > 0 {
+		key, ok := kv[0].(string)
+		if !ok || len(kv) == 1 {
+			fields = append(fields, Field{Key: badKey, Value: kv[0]})
+			kv = kv[1:]
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: kv[1]})
+		kv = kv[2:]
+	}
+	return fields
+}
+
+// GetFields merges the fields attached to every layer of err.
+// When a key is set more than once the outermost value wins.
+// Use GetAllFields to collect every value.
+func GetFields(err error) map[string]any {
+	res := make(map[string]any)
+	all := GetAllErrorInfo[Fields](err)
+	for i := len(all) - 1; i >= 0; i-- {
+		for _, field := range all[i] {
+			res[field.Key] = field.Value
+		}
+	}
+	return res
+}
+
+// GetAllFields collects every value set for each key across the layers of
+// err, outermost first.
+func GetAllFields(err error) map[string][]any {
+	res := make(map[string][]any)
+	for _, fields := range GetAllErrorInfo[Fields](err) {
+		for _, field := range fields {
+			res[field.Key] = append(res[field.Key], field.Value)
+		}
+	}
+	return res
+}
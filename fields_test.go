@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"encoding/json"
+	goError "errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithFieldsNil(t *testing.T) {
+	if got := WithFields(nil, "key", "value"); got != nil {
+		t.Errorf("WithFields(nil): got %#v, expected nil", got)
+	}
+	if got := WithField(nil, "key", "value"); got != nil {
+		t.Errorf("WithField(nil): got %#v, expected nil", got)
+	}
+}
+
+func TestGetFields(t *testing.T) {
+	err := WithFields(goError.New("whoops"), "user_id", 1, "shard", "a")
+	err = WithMessage(err, "msg")
+	err = WithField(err, "user_id", 2)
+	err = WithFields(err, 3, "dangling")
+
+	if err.Error() != "msg: whoops" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+
+	want := map[string]any{"user_id": 2, "shard": "a", badKey: "dangling"}
+	if got := GetFields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFields: got %v, want %v", got, want)
+	}
+
+	wantAll := map[string][]any{"user_id": {2, 1}, "shard": {"a"}, badKey: {3, "dangling"}}
+	if got := GetAllFields(err); !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("GetAllFields: got %v, want %v", got, wantAll)
+	}
+
+	if got := GetFields(goError.New("whoops")); len(got) != 0 {
+		t.Errorf("GetFields without fields: got %v", got)
+	}
+}
+
+func TestFieldsOutput(t *testing.T) {
+	err := WithFields(goError.New("whoops"), "user_id", 1, "shard", "a")
+
+	if got := fmt.Sprintf("%+v", err); !strings.HasPrefix(got, "user_id=1 shard=a\n") {
+		t.Errorf("Sprintf(%%+v): got %q", got)
+	}
+
+	data, e := ToJSON(err)
+	if e != nil || !strings.Contains(string(data), `"info":{"user_id":1,"shard":"a"}`) {
+		t.Errorf("ToJSON: got %s %v", data, e)
+	}
+
+	res := logJSON(t, jsonHandler, slog.Any("err", err))
+	fields, _ := res["err"].(map[string]any)["fields"].(map[string]any)
+	if fields["user_id"] != float64(1) || fields["shard"] != "a" {
+		t.Errorf("slog: got %v", res["err"])
+	}
+	if _, ok := res["err"].(map[string]any)["Fields"]; ok {
+		t.Errorf("slog: fields logged twice")
+	}
+
+	if _, e := json.Marshal(Fields{{Key: "f", Value: func() {}}}); e == nil {
+		t.Errorf("MarshalJSON: no error for an unmarshallable value")
+	}
+}
@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"path"
+)
+
+type fingerprintConfig struct {
+	lines bool
+}
+
+// FingerprintOption changes what Fingerprint takes into account.
+type FingerprintOption func(*fingerprintConfig)
+
+// FingerprintLines makes Fingerprint include the line numbers of the origin
+// stack, so errors raised from different lines of a function differ.
+// Fingerprints then change whenever the code above those lines is edited.
+func FingerprintLines() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.lines = true
+	}
+}
+
+// Fingerprint returns a stable hash of err, suited to group occurrences of
+// the same error. It is built from:
+//
+//   - the function names and file names, without directories, of the frames
+//     of the origin stack, see GetStack
+//   - the Go types of the ErrorInfo attached to err, apart from the ones of
+//     the messages and of Wrap
+//   - the Go type of the root cause, see Cause
+//
+// Messages are ignored, so values formatted by WithMessagef or Errorf do not
+// change the fingerprint. If err is nil, Fingerprint returns "".
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+	var config fingerprintConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	h := fnv.New64a()
+	if stack, ok := GetStack(err); ok {
+		for _, frame := range stack.StackTrace() {
+			io.WriteString(h, frame.FullFuncName())
+			io.WriteString(h, "\x00")
+			io.WriteString(h, path.Base(frame.File()))
+			if config.lines {
+				fmt.Fprintf(h, ":%d", frame.Line())
+			}
+			io.WriteString(h, "\x00")
+		}
+	}
+	walk(err, func(err error) bool {
+		if l, ok := err.(layer); ok {
+			switch info := l.layerInfo().(type) {
+			case emptyInfo, message:
+			default:
+				fmt.Fprintf(h, "%T\x00", info)
+			}
+		}
+		return true
+	})
+	fmt.Fprintf(h, "%T", Cause(err))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
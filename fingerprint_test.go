@@ -0,0 +1,47 @@
+package errors
+
+import (
+	goError "errors"
+	"io"
+	"testing"
+)
+
+func findUser(id int) error {
+	return WithMessagef(io.EOF, "user %d not found", id)
+}
+
+func findOrder(id int) error {
+	return WithMessagef(io.EOF, "order %d not found", id)
+}
+
+func TestFingerprint(t *testing.T) {
+	if got := Fingerprint(findUser(1)); got != Fingerprint(findUser(2)) {
+		t.Errorf("same origin with different messages: fingerprints differ")
+	}
+	if Fingerprint(findUser(1)) == Fingerprint(findOrder(1)) {
+		t.Errorf("different origins: fingerprints are equal")
+	}
+	if Fingerprint(findUser(1)) == Fingerprint(WithErrorInfo(findUser(1), CodeInfo{Code: 100})) {
+		t.Errorf("different ErrorInfo types: fingerprints are equal")
+	}
+	if Fingerprint(findUser(1)) != Fingerprint(WithMessage(findUser(1), "more context")) {
+		t.Errorf("extra message: fingerprints differ")
+	}
+	if Fingerprint(goError.New("a")) == Fingerprint(customErr{msg: "a"}) {
+		t.Errorf("different cause types: fingerprints are equal")
+	}
+	if Fingerprint(nil) != "" {
+		t.Errorf("Fingerprint(nil): got %q", Fingerprint(nil))
+	}
+}
+
+func TestFingerprintLines(t *testing.T) {
+	first := New("whoops")
+	second := New("whoops")
+	if Fingerprint(first) != Fingerprint(second) {
+		t.Errorf("without lines: fingerprints differ")
+	}
+	if Fingerprint(first, FingerprintLines()) == Fingerprint(second, FingerprintLines()) {
+		t.Errorf("with lines: fingerprints are equal")
+	}
+}
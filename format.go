@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// layer is implemented by every fundamental regardless of its ErrorInfo type.
+type layer interface {
+	layerText() string
+	layerInfo() ErrorInfo
+}
+
+// formatChain writes the text of every layer of err, outermost first and one
+// per line followed by the frame of the call that created it, and then the
+// deepest stack found in the chain.
+// Errors joining several causes are rendered as a tree, see formatBranch.
+func formatChain(w io.Writer, err error) {
+	lines, branches := layerLines(err)
+	for i, line := range lines {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, line.text)
+		if line.site != nil {
+			fmt.Fprintf(w, "\n  at %s", siteText(line.site))
+		}
+	}
+	for _, branch := range branches {
+		formatBranch(w, branch, "")
+	}
+	if stack, ok := GetStack(err); ok {
+		formatStack(w, stack)
+	}
+}
+
+// formatBranch writes err as a branch of a tree, on lines prefixed by indent:
+// its message, the frame it originates from and then its own branches.
+func formatBranch(w io.Writer, err error, indent string) {
+	lines, branches := layerLines(err)
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	io.WriteString(w, "\n"+indent+"- "+strings.Join(texts, ": "))
+	if frame, ok := GetStackCause(err); ok {
+		fmt.Fprintf(w, "\n%s  at %s", indent, siteText(frame))
+	}
+	for _, branch := range branches {
+		formatBranch(w, branch, indent+"  ")
+	}
+}
+
+// siteText formats frame as <funcname> (<file>:<line>).
+func siteText(frame Frame) string {
+	return fmt.Sprintf("%s (%s:%d)", frame.FullFuncName(), frame.File(), frame.Line())
+}
+
+// layerLine is the text a layer adds on its own, with the frame of the call
+// that created it when known.
+type layerLine struct {
+	text string
+	site Frame
+}
+
+// layerLines returns the layers of err with a non-empty text, outermost first.
+// When the chain reaches an error joining several causes, the walk stops there
+// and its non-nil causes are returned as branches.
+func layerLines(err error) (lines []layerLine, branches []error) {
+	lines = make([]layerLine, 0)
+	for err != nil {
+		if multi, ok := err.(multiUnwraper); ok {
+			for _, branch := range multi.Unwrap() {
+				if branch != nil {
+					branches = append(branches, branch)
+				}
+			}
+			lines = append(lines, layerLine{text: joinedText(err, len(branches))})
+			break
+		}
+		l, ok := err.(layer)
+		if !ok {
+			// a foreign error already includes the text of its causes
+			lines = append(lines, layerLine{text: err.Error()})
+			break
+		}
+		if text := l.layerText(); text != "" {
+			line := layerLine{text: text}
+			if ins, hit := err.(HasWrapSite); hit {
+				line.site = ins.WrapSite()
+			}
+			lines = append(lines, line)
+		}
+		cause, unwrap := err.(unwraper)
+		if !unwrap {
+			break
+		}
+		err = cause.Unwrap()
+	}
+	return lines, branches
+}
+
+// joinedText returns the text shown for an error joining n causes. A single
+// line message like the one of fmt.Errorf("%w, %w") is kept, while the
+// multiline ones of Join and errors.Join are replaced by a count.
+func joinedText(err error, n int) string {
+	if _, ok := err.(*joinError); !ok {
+		if msg := err.Error(); !strings.Contains(msg, "\n") {
+			return msg
+		}
+	}
+	return fmt.Sprintf("%d errors", n)
+}
+
+// formatStack renders stack the same way pcStack.Format does for %+v.
+func formatStack(w io.Writer, stack Stack) {
+	if f, ok := stack.(fmt.Formatter); ok {
+		fmt.Fprintf(w, "%+v", f)
+		return
+	}
+	for _, frame := range stack.StackTrace() {
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.FullFuncName(), frame.File(), frame.Line())
+	}
+	io.WriteString(w, "\n")
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	err := New("whoops")
+	err = WithMessage(err, "msg1")
+	err = WithErrorInfo(err, CodeInfo{Code: 100})
+	err = WithMessagef(err, "msg%d", 2)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "msg2: msg1: whoops"},
+		{"%v", "msg2: msg1: whoops"},
+		{"%q", `"msg2: msg1: whoops"`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("Sprintf(%q): got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	got := fmt.Sprintf("%+v", err)
+	frame, _ := GetStackCause(err)
+	file := frame.File()
+	want := "msg2\n  at github.com/mochi-c/errors.TestFormat (" + file + ":13)" +
+		"\n100\n  at github.com/mochi-c/errors.TestFormat (" + file + ":12)" +
+		"\nmsg1\n  at github.com/mochi-c/errors.TestFormat (" + file + ":11)" +
+		"\nwhoops\ngithub.com/mochi-c/errors.TestFormat\n\t" + file + ":10\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("Sprintf(%%+v): got %q, want prefix %q", got, want)
+	}
+}
+
+// nilUnsafeInfo expects a non-nil cause, like many ErrorInfo types written
+// before layers were rendered one by one.
+type nilUnsafeInfo struct{}
+
+func (nilUnsafeInfo) WhenError(cause error) string {
+	return "unsafe: " + cause.Error()
+}
+
+func TestFormatNilUnsafeInfo(t *testing.T) {
+	err := WithMessage(WithErrorInfo(New("whoops"), nilUnsafeInfo{}), "msg")
+
+	got := fmt.Sprintf("%+v", err)
+	if want := "msg\n  at "; !strings.HasPrefix(got, want) || !strings.Contains(got, "\nunsafe\n  at ") {
+		t.Errorf("Sprintf(%%+v): got %q", got)
+	}
+	data, e := ToJSON(err)
+	if e != nil || !strings.Contains(string(data), `"text":"unsafe"`) {
+		t.Errorf("ToJSON: got %s %v", data, e)
+	}
+	for name, codec := range map[string]struct {
+		encode func(error) ([]byte, error)
+		decode func([]byte) (error, error)
+	}{
+		"json":   {EncodeJSON, DecodeJSON},
+		"binary": {EncodeBinary, DecodeBinary},
+	} {
+		data, _ := codec.encode(err)
+		got, e := codec.decode(data)
+		if e != nil || got.Error() != err.Error() {
+			t.Errorf("%s: got %v %v, want %q", name, got, e, err.Error())
+		}
+	}
+}
@@ -16,44 +16,57 @@ type Frame interface {
 	FuncName() string
 }
 
-// Frame represents a program counter inside a stack frame.
-// For historical reasons if Frame is interpreted as a uintptr
-// its value represents the program counter + 1.
-type pcFrame uintptr
+// pcFrame represents a stack frame as resolved by runtime.CallersFrames.
+// Calls inlined by the compiler get a pcFrame of their own, with the
+// function, File and Line of the inlined code.
+type pcFrame struct {
+	pc   uintptr
+	file string
+	line int
+	fn   string
+}
+
+// newPcFrame returns the frame for the location described by frame.
+func newPcFrame(frame runtime.Frame) pcFrame {
+	return pcFrame{
+		pc:   frame.PC,
+		file: frame.File,
+		line: frame.Line,
+		fn:   frame.Function,
+	}
+}
+
+// frameForPC returns the frame of a pc recorded by runtime.Callers.
+func frameForPC(pc uintptr) pcFrame {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return newPcFrame(frame)
+}
 
 // Pc returns the program counter for this frame;
 // multiple frames may have the same PC value.
-func (f pcFrame) Pc() uintptr { return uintptr(f) - 1 }
+func (f pcFrame) Pc() uintptr { return f.pc }
 
 // File returns the full path to the File that contains the
 // function for this Frame's Pc.
 func (f pcFrame) File() string {
-	fn := runtime.FuncForPC(f.Pc())
-	if fn == nil {
+	if f.file == "" {
 		return "unknown"
 	}
-	file, _ := fn.FileLine(f.Pc())
-	return file
+	return f.file
 }
 
 // Line returns the Line number of source code of the
 // function for this Frame's Pc.
 func (f pcFrame) Line() int {
-	fn := runtime.FuncForPC(f.Pc())
-	if fn == nil {
-		return 0
-	}
-	_, line := fn.FileLine(f.Pc())
-	return line
+	return f.line
 }
 
 // FullFuncName returns the Name of this function, if known.
 func (f pcFrame) FullFuncName() string {
-	fn := runtime.FuncForPC(f.Pc())
-	if fn == nil {
+	if f.fn == "" {
 		return "unknown"
 	}
-	return fn.Name()
+	return f.fn
 }
 
 // Format formats the frame according to the fmt.Formatter interface.
@@ -0,0 +1,45 @@
+package errors
+
+import (
+	goError "errors"
+	"runtime"
+	"testing"
+)
+
+// inlinedWrap is small enough for the compiler to inline it, along with
+// CapturePolicy.Wrap, into its caller.
+func inlinedWrap(err error) error {
+	return CapturePolicy{}.Wrap(err)
+}
+
+func TestStackTraceInlined(t *testing.T) {
+	err := inlinedWrap(goError.New("whoops"))
+
+	stack, _ := GetStack(err)
+	frames := runtime.CallersFrames(*stack.(*pcStack))
+	inner, _ := frames.Next()
+	outer, _ := frames.Next()
+	if inner.Entry != outer.Entry {
+		// inlined frames share the entry of the function they are inlined in
+		t.Skip("inlinedWrap was not inlined, build without -gcflags=-l")
+	}
+
+	tests := []struct {
+		funcName string
+		line     int
+	}{
+		{"inlinedWrap", 12},
+		{"TestStackTraceInlined", 16},
+	}
+	trace := stack.StackTrace()
+	for i, tt := range tests {
+		if trace[i].FuncName() != tt.funcName || trace[i].Line() != tt.line {
+			t.Errorf("frame %d: got %s:%d, want %s:%d", i, trace[i].FuncName(), trace[i].Line(), tt.funcName, tt.line)
+		}
+	}
+
+	frame, _ := GetStackCause(err)
+	if frame.FuncName() != "inlinedWrap" || frame.Line() != 12 || frame.File() != trace[0].File() {
+		t.Errorf("GetStackCause: got %s %s:%d", frame.FuncName(), frame.File(), frame.Line())
+	}
+}
@@ -1,5 +1,11 @@
 package errors
 
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
 type ErrorInfo interface {
 	WhenError(cause error) string
 }
@@ -8,10 +14,21 @@ type unwraper interface {
 	Unwrap() error
 }
 
+// multiUnwraper is implemented by errors joining several causes,
+// such as those returned by errors.Join or fmt.Errorf with many %w verbs.
+type multiUnwraper interface {
+	Unwrap() []error
+}
+
 type HasStack interface {
 	GetStack() Stack
 }
 
+// HasWrapSite is implemented by layers knowing the call that created them.
+type HasWrapSite interface {
+	WrapSite() Frame
+}
+
 type Fundamental[T any] interface {
 	HasStack
 	GetErrorInfo() T
@@ -21,6 +38,8 @@ type fundamental[T ErrorInfo] struct {
 	cause error
 	info  T
 	stack Stack
+	// site is the pc of the call that created this layer, 0 if unknown.
+	site uintptr
 }
 
 func (f *fundamental[T]) GetErrorInfo() T {
@@ -31,6 +50,25 @@ func (f *fundamental[T]) GetStack() Stack {
 	return f.stack
 }
 
+// StackTrace returns the frames of the stack found by GetStack, so the error
+// satisfies the stackTracer interface of github.com/pkg/errors.
+func (f *fundamental[T]) StackTrace() StackTrace {
+	stack := f.GetStack()
+	if stack == nil {
+		return nil
+	}
+	return stack.StackTrace()
+}
+
+// WrapSite returns the frame of the call that created this layer,
+// or nil when the stack capture was disabled.
+func (f *fundamental[T]) WrapSite() Frame {
+	if f.site == 0 {
+		return nil
+	}
+	return frameForPC(f.site)
+}
+
 func (f *fundamental[T]) Error() string {
 	return f.info.WhenError(f.cause)
 }
@@ -40,11 +78,72 @@ func (f *fundamental[T]) Unwrap() error {
 	return f.cause
 }
 
+// Is reports whether target is the Sentinel this layer was created from,
+// so errors.Is matches the errors returned by Sentinel.Throw and Sentinel.Wrap.
+func (f *fundamental[T]) Is(target error) bool {
+	s, ok := any(f.info).(*Sentinel)
+	return ok && s.is(target)
+}
+
+// layerText returns the text this layer adds on its own, without its cause,
+// given by WhenError(nil). ErrorInfo types written before layers were
+// rendered one by one may not expect a nil cause: when WhenError panics, the
+// text is the message of the layer with the message of its cause cut off the
+// end, such as "msg" for "msg: cause".
+func (f *fundamental[T]) layerText() (text string) {
+	if f.cause == nil {
+		return f.Error()
+	}
+	defer func() {
+		if recover() != nil {
+			text = f.Error()
+			if cause := f.cause.Error(); strings.HasSuffix(text, cause) {
+				text = strings.TrimSuffix(strings.TrimSuffix(text, cause), ": ")
+			}
+		}
+	}()
+	return f.info.WhenError(nil)
+}
+
+func (f *fundamental[T]) layerInfo() ErrorInfo {
+	return f.info
+}
+
+// Format formats the error according to the fmt.Formatter interface.
+//
+//	%s    the error message, same as Error()
+//	%q    the double-quoted error message
+//	%v    equivalent to %s
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+v   the text of every layer, outermost first, one per line,
+//	      followed by the deepest stack
+func (f *fundamental[T]) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatChain(s, f)
+			return
+		}
+		io.WriteString(s, f.Error())
+	case 's':
+		io.WriteString(s, f.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", f.Error())
+	}
+}
+
+// MarshalJSON encodes the error chain, see ToJSON.
+func (f *fundamental[T]) MarshalJSON() ([]byte, error) {
+	return ToJSON(f)
+}
+
 func WithErrorInfo[T ErrorInfo](err error, info T) error {
-	return withErrorInfo(err, info)
+	return withErrorInfo(err, info, loadCapturePolicy())
 }
 
-func withErrorInfo[T ErrorInfo](err error, info T) error {
+func withErrorInfo[T ErrorInfo](err error, info T, policy CapturePolicy) error {
 	if err == nil {
 		return nil
 	}
@@ -53,22 +152,26 @@ func withErrorInfo[T ErrorInfo](err error, info T) error {
 			cause: err,
 			info:  info,
 			stack: stack,
+			site:  callerPC(4, policy),
 		}
 	} else {
-		stack = callers(4)
+		stack = callers(4, policy)
 		return &fundamental[T]{
 			cause: err,
 			info:  info,
 			stack: stack,
+			site:  callerPC(4, policy),
 		}
 	}
 
 }
 
 func newFundamental[T ErrorInfo](info T) error {
+	policy := loadCapturePolicy()
 	return &fundamental[T]{
 		cause: nil,
 		info:  info,
-		stack: callers(4),
+		stack: callers(4, policy),
+		site:  callerPC(4, policy),
 	}
 }
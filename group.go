@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// SpawnInfo is the ErrorInfo attached by Group to the errors of the
+// goroutines it started, with the stack of the goroutine that started them.
+type SpawnInfo struct {
+	Stack Stack
+}
+
+func (info SpawnInfo) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else if info.Stack == nil {
+		return "spawned"
+	} else {
+		return "spawned at " + siteText(info.Stack.StackSource())
+	}
+}
+
+// MarshalJSON encodes the spawn stack as the stack of ToJSON.
+func (info SpawnInfo) MarshalJSON() ([]byte, error) {
+	var stack []jsonFrame
+	if info.Stack != nil {
+		stack = newJSONStack(info.Stack)
+	}
+	return json.Marshal(struct {
+		Stack []jsonFrame `json:"stack"`
+	}{stack})
+}
+
+// LogValue implements slog.LogValuer with the spawn site as origin.
+func (info SpawnInfo) LogValue() slog.Value {
+	if info.Stack == nil {
+		return slog.GroupValue()
+	}
+	return slog.GroupValue(slog.String("origin", frameText(info.Stack.StackSource())))
+}
+
+// Group runs goroutines and collects their errors, like errgroup.Group.
+// The zero value is ready to use and does not cancel anything on error.
+//
+// Since a stack only covers the goroutine it was captured in, Go records
+// the stack of its caller. The errors returned by Wait carry it in a
+// SpawnInfo, next to the stack of the failure in the goroutine itself.
+type Group struct {
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errs   []error
+	cancel context.CancelFunc
+}
+
+// GroupWithContext returns a new Group and a context derived from ctx,
+// canceled the first time a goroutine of the Group fails or when Wait
+// returns, whichever occurs first.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go calls fn in a new goroutine. A panic in fn is recovered and turned into
+// an error, see Recover.
+func (g *Group) Go(fn func() error) {
+	spawn := callers(3, loadCapturePolicy())
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := Try(fn); err != nil {
+			g.fail(withSpawnInfo(err, spawn))
+		}
+	}()
+}
+
+func withSpawnInfo(err error, spawn Stack) error {
+	stack, ok := GetStack(err)
+	if !ok {
+		stack = spawn
+	}
+	return &fundamental[SpawnInfo]{
+		cause: err,
+		info:  SpawnInfo{Stack: spawn},
+		stack: stack,
+	}
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errs = append(g.errs, err)
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// Wait blocks until every goroutine started by Go has returned. It returns
+// nil if none failed, the error of the goroutine if only one did, or else an
+// error joining all of them in the order they failed, see Join.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch len(g.errs) {
+	case 0:
+		return nil
+	case 1:
+		return g.errs[0]
+	default:
+		return &joinError{
+			errs:  g.errs,
+			stack: callers(3, loadCapturePolicy()),
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	var g Group
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait without error: got %v", err)
+	}
+
+	g.Go(func() error { return io.EOF })
+	err := g.Wait()
+	if err == nil || err.Error() != "EOF" {
+		t.Fatalf("Wait: got %v", err)
+	}
+	info, ok := GetErrorInfo[SpawnInfo](err)
+	if !ok || info.Stack.StackSource().FuncName() != "TestGroup" || info.Stack.StackSource().Line() != 18 {
+		t.Errorf("SpawnInfo: got %v %v, want TestGroup:18", info, ok)
+	}
+	if frame, _ := GetStackCause(err); frame.Line() != 18 {
+		t.Errorf("GetStackCause of an error without stack: got line %d, want 18", frame.Line())
+	}
+}
+
+func TestGroupStacks(t *testing.T) {
+	var g Group
+	g.Go(func() error { return New("first") })
+	g.Go(func() error { panic("second") })
+	err := g.Wait()
+
+	branches, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(branches.Unwrap()) != 2 {
+		t.Fatalf("Wait: got %v, want two joined errors", err)
+	}
+	for _, branch := range branches.Unwrap() {
+		frame, _ := GetStackCause(branch)
+		info, _ := GetErrorInfo[SpawnInfo](branch)
+		spawn := info.Stack.StackSource()
+		switch branch.Error() {
+		case "first":
+			if frame.FuncName() != "TestGroupStacks.func1" || spawn.Line() != 34 {
+				t.Errorf("first: got failure %s, spawn line %d", frame.FuncName(), spawn.Line())
+			}
+		case "panic: second":
+			if frame.FuncName() != "TestGroupStacks.func2" || spawn.Line() != 35 {
+				t.Errorf("second: got failure %s, spawn line %d", frame.FuncName(), spawn.Line())
+			}
+		default:
+			t.Errorf("unexpected error %v", branch)
+		}
+	}
+	if frame, _ := GetStackCause(err); frame.FuncName() != "TestGroupStacks" || frame.Line() != 36 {
+		t.Errorf("join stack: got %s:%d, want TestGroupStacks:36", frame.FuncName(), frame.Line())
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "spawned at github.com/mochi-c/errors.TestGroupStacks (") {
+		t.Errorf("Sprintf(%%+v): got %q", got)
+	}
+}
+
+func TestGroupWithContext(t *testing.T) {
+	g, ctx := GroupWithContext(context.Background())
+	g.Go(func() error { return io.EOF })
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+	if err := g.Wait(); err == nil || err.Error() != "EOF" {
+		t.Errorf("Wait: got %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Errorf("context not canceled")
+	}
+}
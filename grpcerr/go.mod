@@ -0,0 +1,16 @@
+module github.com/mochi-c/errors/grpcerr
+
+go 1.21
+
+require (
+	github.com/mochi-c/errors v0.0.0-20261018110715-fb32b600f2e0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.1
+)
+
+require (
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+)
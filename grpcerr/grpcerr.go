@@ -0,0 +1,218 @@
+// Package grpcerr converts errors built with github.com/mochi-c/errors to and
+// from gRPC statuses, and provides interceptors doing it for every call.
+package grpcerr
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mochi-c/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Code is the ErrorInfo holding the gRPC code an error maps to.
+type Code codes.Code
+
+// WhenError leaves the message of cause unchanged.
+func (c Code) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "gRPC " + codes.Code(c).String()
+	}
+}
+
+// WithCode annotates err with the gRPC code it maps to.
+// If err is nil, WithCode returns nil.
+func WithCode(err error, code codes.Code) error {
+	return errors.WithErrorInfo(err, Code(code))
+}
+
+// RemoteOrigin is the ErrorInfo holding the origin frame of an error
+// received from a gRPC peer, as <funcname> <file>:<line>.
+type RemoteOrigin string
+
+// WhenError leaves the message of cause unchanged.
+func (o RemoteOrigin) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "from " + string(o)
+	}
+}
+
+// CodeOf returns the gRPC code err maps to: the outermost Code attached to
+// err, else the one registered for its errors.Code, else Canceled or
+// DeadlineExceeded for the context errors, else the code of a gRPC status
+// error in its chain, else Unknown. CodeOf(nil) is OK.
+func CodeOf(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if code, ok := errors.GetErrorInfo[Code](err); ok {
+		return codes.Code(code)
+	}
+	if code, ok := errors.GetCode(err); ok {
+		if def, ok := errors.LookupCode(code); ok && def.GRPCCode != uint32(codes.OK) {
+			return codes.Code(def.GRPCCode)
+		}
+	}
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return codes.Canceled
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if stderrors.As(err, &se) {
+		return se.GRPCStatus().Code()
+	}
+	return codes.Unknown
+}
+
+// ToStatus converts err to a gRPC status, with the code given by CodeOf and
+// the message of err. Its details hold an errdetails.ErrorInfo with the
+// fields of err as metadata and the name of its registered errors.Code as
+// reason, and an errdetails.DebugInfo with the origin of err: its
+// RemoteOrigin if it was received from a peer, else its origin frame.
+// When err wraps a gRPC status error whose code is the one of CodeOf, the
+// status keeps its message, prefixed by the messages of the layers above it,
+// and its details, followed by those of err it does not carry already.
+// ToStatus(nil) is an OK status.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	code := CodeOf(err)
+	st := status.New(code, err.Error())
+	var hasInfo, hasDebug bool
+	if inner, msg, ok := wrappedStatus(err); ok && inner.Code() == code {
+		p := inner.Proto()
+		p.Message = msg
+		st = status.FromProto(p)
+		for _, detail := range st.Details() {
+			switch detail.(type) {
+			case *errdetails.ErrorInfo:
+				hasInfo = true
+			case *errdetails.DebugInfo:
+				hasDebug = true
+			}
+		}
+	}
+
+	var details []protoadapt.MessageV1
+	if !hasInfo {
+		info := &errdetails.ErrorInfo{}
+		if code, ok := errors.GetCode(err); ok {
+			if def, ok := errors.LookupCode(code); ok {
+				info.Reason = def.Name
+			}
+		}
+		if fields := errors.GetFields(err); len(fields) > 0 {
+			info.Metadata = make(map[string]string, len(fields))
+			for key, value := range fields {
+				info.Metadata[key] = fmt.Sprint(value)
+			}
+		}
+		if info.Reason != "" || info.Metadata != nil {
+			details = append(details, info)
+		}
+	}
+	if !hasDebug {
+		if origin, ok := errors.GetErrorInfo[RemoteOrigin](err); ok {
+			details = append(details, &errdetails.DebugInfo{StackEntries: []string{string(origin)}})
+		} else if frame, ok := errors.GetStackCause(err); ok {
+			details = append(details, &errdetails.DebugInfo{
+				StackEntries: []string{fmt.Sprintf("%s %s:%d", frame.FullFuncName(), frame.File(), frame.Line())},
+			})
+		}
+	}
+	if len(details) == 0 {
+		return st
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// wrappedStatus returns the status of the first gRPC status error of the
+// chain of err, and its message prefixed by the message of every error above
+// it, without the message of its cause, such as "msg" for "msg: cause".
+func wrappedStatus(err error) (*status.Status, string, bool) {
+	var texts []string
+	for err != nil {
+		if se, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+			st := se.GRPCStatus()
+			return st, strings.Join(append(texts, st.Message()), ": "), true
+		}
+		cause := stderrors.Unwrap(err)
+		text := err.Error()
+		if cause != nil && strings.HasSuffix(text, cause.Error()) {
+			text = strings.TrimSuffix(strings.TrimSuffix(text, cause.Error()), ": ")
+		}
+		if text != "" {
+			texts = append(texts, text)
+		}
+		err = cause
+	}
+	return nil, "", false
+}
+
+// statusError is the root cause of the errors built by FromStatus. It keeps
+// the received status, so status.FromError and status.Code still work.
+type statusError struct {
+	st *status.Status
+}
+
+func (e *statusError) Error() string {
+	return e.st.Message()
+}
+
+func (e *statusError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// FromStatus converts a status received from a gRPC peer to an error, with the
+// message of st, its code as a Code, the metadata of its errdetails.ErrorInfo
+// as fields and the first entry of its errdetails.DebugInfo as RemoteOrigin.
+// The cause of the error holds st, see status.FromError, and its stack is
+// the one of the caller of FromStatus.
+// FromStatus returns nil for an OK status.
+func FromStatus(st *status.Status) error {
+	return fromStatus(st, 2)
+}
+
+// fromStatus is FromStatus recording the stack from skip frames above it.
+func fromStatus(st *status.Status, skip int) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	policy := errors.GetCapturePolicy()
+	policy.Skip += skip
+	err := policy.Wrap(&statusError{st: st})
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			keys := make([]string, 0, len(detail.Metadata))
+			for key := range detail.Metadata {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				err = errors.WithField(err, key, detail.Metadata[key])
+			}
+		case *errdetails.DebugInfo:
+			if len(detail.StackEntries) > 0 {
+				err = errors.WithErrorInfo(err, RemoteOrigin(detail.StackEntries[0]))
+			}
+		}
+	}
+	return WithCode(err, st.Code())
+}
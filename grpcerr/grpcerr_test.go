@@ -0,0 +1,214 @@
+package grpcerr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mochi-c/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var codeTestUnavailable = errors.RegisterCode(-503, "UNAVAILABLE", "unavailable", 503, uint32(codes.Unavailable))
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"plain", errors.New("whoops"), codes.Unknown},
+		{"code", WithCode(errors.New("whoops"), codes.NotFound), codes.NotFound},
+		{"registered", errors.WithCode(io.EOF, codeTestUnavailable), codes.Unavailable},
+		{"context", fmt.Errorf("call: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"status", errors.Wrap(status.Error(codes.Aborted, "aborted")), codes.Aborted},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	if st := ToStatus(nil); st.Code() != codes.OK {
+		t.Errorf("ToStatus(nil): got %v", st.Code())
+	}
+	if err := FromStatus(status.New(codes.OK, "")); err != nil {
+		t.Errorf("FromStatus(OK): got %v", err)
+	}
+
+	sent := errors.WithFields(errors.New("whoops"), "user_id", "u1")
+	sent = errors.WithCode(errors.WithMessage(sent, "msg"), codeTestUnavailable)
+
+	got := FromStatus(ToStatus(sent))
+	assertReceived(t, got, sent, codes.Unavailable)
+}
+
+func assertReceived(t *testing.T, got, sent error, code codes.Code) {
+	t.Helper()
+	if got.Error() != sent.Error() {
+		t.Errorf("Error(): got %q, want %q", got.Error(), sent.Error())
+	}
+	if CodeOf(got) != code || status.Code(got) != code {
+		t.Errorf("code: got %v and %v, want %v", CodeOf(got), status.Code(got), code)
+	}
+	if fields := errors.GetFields(got); fields["user_id"] != "u1" {
+		t.Errorf("GetFields: got %v", fields)
+	}
+	frame, _ := errors.GetStackCause(sent)
+	origin, ok := errors.GetErrorInfo[RemoteOrigin](got)
+	if want := fmt.Sprintf("%s %s:%d", frame.FullFuncName(), frame.File(), frame.Line()); !ok || string(origin) != want {
+		t.Errorf("RemoteOrigin: got %q, want %q", origin, want)
+	}
+}
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func dial(t *testing.T, err error) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, &healthServer{err: err})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, e := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if e != nil {
+		t.Fatalf("NewClient: %v", e)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	sent := WithCode(errors.WithFields(errors.New("down"), "user_id", "u1"), codes.Unavailable)
+	client := dial(t, sent)
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err == nil {
+		t.Fatal("Check: got no error")
+	}
+	assertReceived(t, err, sent, codes.Unavailable)
+	if _, ok := errors.GetStack(err); !ok {
+		t.Errorf("GetStack: no stack on the client error")
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	sent := errors.WithFields(errors.WithCode(errors.New("down"), codeTestUnavailable), "user_id", "u1")
+	client := dial(t, sent)
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first Recv: %v", err)
+	}
+	_, err = stream.Recv()
+	if err == nil {
+		t.Fatal("Recv: got no error")
+	}
+	assertReceived(t, err, sent, codes.Unavailable)
+	if !strings.Contains(fmt.Sprintf("%+v", err), "from github.com/mochi-c/errors/grpcerr.TestStreamInterceptors") {
+		t.Errorf("Sprintf(%%+v): got %q", fmt.Sprintf("%+v", err))
+	}
+}
+
+func TestStreamEOF(t *testing.T) {
+	client := dial(t, nil)
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	stream.Recv()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv at the end: got %v, want io.EOF", err)
+	}
+}
+
+func TestUnaryStatusDetails(t *testing.T) {
+	st, _ := status.New(codes.InvalidArgument, "bad name").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "empty"}},
+	})
+	client := dial(t, errors.WithMessage(st.Err(), "validate"))
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	got := status.Convert(err)
+	if got.Code() != codes.InvalidArgument || got.Message() != "validate: bad name" {
+		t.Errorf("status: got %v %q, want %v %q", got.Code(), got.Message(), codes.InvalidArgument, "validate: bad name")
+	}
+	if err.Error() != "validate: bad name" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	var badRequest *errdetails.BadRequest
+	for _, detail := range got.Details() {
+		if detail, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = detail
+		}
+	}
+	if badRequest == nil || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "name" {
+		t.Errorf("details: got %v, want the BadRequest sent", got.Details())
+	}
+}
+
+func TestStatusForwarded(t *testing.T) {
+	sent := errors.WithFields(errors.New("whoops"), "user_id", "u1")
+	sent = errors.WithCode(sent, codeTestUnavailable)
+	first := ToStatus(sent)
+
+	// a proxy receiving the status and returning it as is
+	received := FromStatus(first)
+	if frame, ok := errors.GetStackCause(received); !ok || frame.FuncName() != "TestStatusForwarded" {
+		t.Errorf("GetStackCause: got %v %v, want TestStatusForwarded", frame, ok)
+	}
+	forwarded := ToStatus(errors.WithMessage(received, "proxy"))
+	if len(forwarded.Details()) != len(first.Details()) {
+		t.Errorf("details: got %v, want %v", forwarded.Details(), first.Details())
+	}
+	got := FromStatus(forwarded)
+	assertReceived(t, got, errors.WithMessage(sent, "proxy"), codes.Unavailable)
+
+	// an origin received from a peer wins over the local one
+	err := errors.WithErrorInfo(errors.New("whoops"), RemoteOrigin("main.main /src/main.go:12"))
+	for _, detail := range ToStatus(err).Details() {
+		if debug, ok := detail.(*errdetails.DebugInfo); ok && debug.StackEntries[0] != "main.main /src/main.go:12" {
+			t.Errorf("DebugInfo: got %v", debug.StackEntries)
+		}
+	}
+}
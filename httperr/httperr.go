@@ -0,0 +1,234 @@
+// Package httperr serves errors built with github.com/mochi-c/errors
+// over HTTP as RFC 7807 problem details.
+package httperr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/mochi-c/errors"
+)
+
+// HTTPStatus is the ErrorInfo holding the HTTP status code an error maps to.
+type HTTPStatus int
+
+// WhenError leaves the message of cause unchanged.
+func (s HTTPStatus) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return fmt.Sprintf("HTTP %d", int(s))
+	}
+}
+
+// WithStatus annotates err with the HTTP status code it maps to.
+// If err is nil, WithStatus returns nil.
+func WithStatus(err error, status int) error {
+	return errors.WithErrorInfo(err, HTTPStatus(status))
+}
+
+// PublicMessage is the ErrorInfo holding a message safe to show to clients.
+type PublicMessage string
+
+// WhenError leaves the message of cause unchanged.
+func (m PublicMessage) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return string(m)
+	}
+}
+
+// WithPublicMessage annotates err with a message safe to show to clients.
+// If err is nil, WithPublicMessage returns nil.
+func WithPublicMessage(err error, msg string) error {
+	return errors.WithErrorInfo(err, PublicMessage(msg))
+}
+
+// StatusOf returns the HTTP status code err maps to: the outermost HTTPStatus
+// attached to err, else the one registered for its Code, else 500.
+func StatusOf(err error) int {
+	if status, ok := errors.GetErrorInfo[HTTPStatus](err); ok {
+		return int(status)
+	}
+	if code, ok := errors.GetCode(err); ok {
+		if def, ok := errors.LookupCode(code); ok && def.HTTPStatus != 0 {
+			return def.HTTPStatus
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+type config struct {
+	logger *slog.Logger
+}
+
+// Option configures Handler and Recover.
+type Option func(*config)
+
+// WithLogger sets the logger errors are logged with, slog.Default by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// serveError logs err with its whole chain and stack, at the level of its
+// Severity or at error level, then writes the problem shown to clients,
+// unless the response has already started.
+func (c *config) serveError(w *responseWriter, r *http.Request, err error) {
+	p := ToProblem(err)
+	level := slog.LevelError
+	if severity, ok := errors.GetSeverity(err); ok {
+		level = severity.Level()
+	}
+	status := p.Status
+	if w.status != 0 {
+		status = w.status
+	}
+	c.log(r.Context(), level, "request failed",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", status),
+		slog.Bool("started", w.status != 0),
+		slog.String("error", fmt.Sprintf("%+v", err)),
+	)
+	if w.status == 0 {
+		writeProblem(w, p)
+	}
+}
+
+// responseWriter records whether the response has started, so a problem
+// is not written over a response already sent in part.
+type responseWriter struct {
+	http.ResponseWriter
+	// status is the status written, 0 until the response has started
+	status int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// informational responses are followed by the final one
+	if w.status == 0 && status >= 200 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the features of the
+// underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, so the middleware does not break the
+// handlers taking over the connection, such as websocket upgrades.
+// It returns an error when the underlying writer does not support it.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httperr: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// ReadFrom implements io.ReaderFrom, so io.Copy to the writer still uses the
+// one of the underlying writer, such as sendfile.
+func (w *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(w.ResponseWriter, src)
+}
+
+func (c *config) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// HandlerFunc is an HTTP handler returning an error.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler adapts fn to an http.Handler. When fn returns an error or panics,
+// the error is logged and a problem is written, see Recover.
+// When fn has started the response, the error is only logged.
+func Handler(fn HandlerFunc, opts ...Option) http.Handler {
+	c := newConfig(opts)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := &responseWriter{ResponseWriter: rw}
+		err := errors.Try(func() error {
+			return fn(w, r)
+		})
+		if err != nil {
+			rethrowAbort(err)
+			c.serveError(w, r, err)
+		}
+	})
+}
+
+// Recover returns a middleware recovering the panics of next. The panic is
+// turned into an error with the stack of the panic site, logged with %+v,
+// and answered with a 500 problem, unless next had started the response:
+// the panic is then only logged.
+// Panics with http.ErrAbortHandler are left to net/http.
+func Recover(next http.Handler, opts ...Option) http.Handler {
+	c := newConfig(opts)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := &responseWriter{ResponseWriter: rw}
+		err := errors.Try(func() error {
+			next.ServeHTTP(w, r)
+			return nil
+		})
+		if err != nil {
+			rethrowAbort(err)
+			c.serveError(w, r, err)
+		}
+	})
+}
+
+// rethrowAbort panics again with http.ErrAbortHandler, which net/http
+// uses to abort a response without logging.
+func rethrowAbort(err error) {
+	if info, ok := errors.GetErrorInfo[errors.PanicInfo](err); ok && info.Value == http.ErrAbortHandler {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,179 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mochi-c/errors"
+)
+
+var codeTestConflict = errors.RegisterCode(-409, "CONFLICT", "conflict", http.StatusConflict, 10)
+
+func serve(t *testing.T, h http.Handler) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	var p Problem
+	if ct := rec.Header().Get("Content-Type"); ct == "application/problem+json" {
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+			t.Fatalf("Unmarshal %s: %v", rec.Body.Bytes(), err)
+		}
+	}
+	return rec, p
+}
+
+func TestHandler(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{"no status", errors.New("db password is hunter2"), 500, ""},
+		{"status", WithStatus(errors.New("missing"), http.StatusNotFound), 404, ""},
+		{"public message", WithPublicMessage(WithStatus(errors.New("bad id"), 400), "invalid id"), 400, "invalid id"},
+		{"code", errors.WithMessage(errors.WithCode(io.EOF, codeTestConflict), "msg"), 409, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			}, WithLogger(logger))
+
+			rec, p := serve(t, h)
+			if rec.Code != tt.status || p.Status != tt.status || p.Title != http.StatusText(tt.status) || p.Detail != tt.detail {
+				t.Errorf("got %d %+v", rec.Code, p)
+			}
+			if strings.Contains(rec.Body.String(), tt.err.Error()) {
+				t.Errorf("body leaks the error message: %s", rec.Body.String())
+			}
+			if !strings.Contains(logs.String(), "httperr_test.go") {
+				t.Errorf("log without stack: %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestHandlerSuccess(t *testing.T) {
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	if rec, _ := serve(t, h); rec.Code != http.StatusNoContent {
+		t.Errorf("got %d", rec.Code)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var logs bytes.Buffer
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	rec, p := serve(t, h)
+	if rec.Code != 500 || p.Status != 500 {
+		t.Errorf("got %d %+v", rec.Code, p)
+	}
+	if !strings.Contains(logs.String(), "panic: boom") || !strings.Contains(logs.String(), "TestRecover.func1") {
+		t.Errorf("log without panic site: %s", logs.String())
+	}
+}
+
+func TestRecoverAbort(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("got %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	serve(t, h)
+}
+
+func TestHandlerSeverity(t *testing.T) {
+	var logs bytes.Buffer
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return WithStatus(errors.WithSeverity(errors.New("missing"), errors.SeverityWarn), http.StatusNotFound)
+	}, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	serve(t, h)
+	if !strings.Contains(logs.String(), "level=WARN") {
+		t.Errorf("log: %s", logs.String())
+	}
+}
+
+func TestRecoverStarted(t *testing.T) {
+	var logs bytes.Buffer
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "partial")
+		panic("boom")
+	}), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	rec, _ := serve(t, h)
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" || rec.Header().Get("Content-Type") == "application/problem+json" {
+		t.Errorf("got %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	if !strings.Contains(logs.String(), "panic: boom") || !strings.Contains(logs.String(), "started=true") {
+		t.Errorf("log: %s", logs.String())
+	}
+}
+
+func TestRecoverHijack(t *testing.T) {
+	var hijackErr error
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("%T does not implement http.Hijacker", w)
+			return
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			hijackErr = err
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || hijackErr != nil {
+		t.Errorf("got %d %v, want 204", resp.StatusCode, hijackErr)
+	}
+
+	// a recorder cannot be hijacked
+	serve(t, h)
+	if hijackErr == nil {
+		t.Errorf("Hijack of a ResponseRecorder: got no error")
+	}
+}
+
+func TestRecoverReadFrom(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Errorf("%T does not implement io.ReaderFrom", w)
+		}
+		io.Copy(w, strings.NewReader("body"))
+		panic("boom")
+	}), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	rec, _ := serve(t, h)
+	if rec.Code != http.StatusOK || rec.Body.String() != "body" {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
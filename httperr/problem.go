@@ -0,0 +1,271 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mochi-c/errors"
+)
+
+// Problem is an RFC 7807 problem details object. Problem is also the
+// ErrorInfo attached by FromProblem, holding the problem as received.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions holds the extension members of the problem.
+	Extensions map[string]any
+}
+
+// WhenError leaves the message of cause unchanged.
+func (p Problem) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return p.Title
+	}
+}
+
+type problemMembers struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// MarshalJSON encodes p with its extension members next to the standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(problemMembers{p.Type, p.Title, p.Status, p.Detail, p.Instance})
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	ext := make(map[string]any, len(p.Extensions))
+	for name, value := range p.Extensions {
+		if !isStandardMember(name) {
+			ext[name] = value
+		}
+	}
+	extData, err := json.Marshal(ext)
+	if err != nil || len(ext) == 0 {
+		return data, err
+	}
+	if len(data) == 2 {
+		return extData, nil
+	}
+	// join {"type":...} and {"ext":...} into {"type":...,"ext":...}
+	data[len(data)-1] = ','
+	return append(data, extData[1:]...), nil
+}
+
+// UnmarshalJSON decodes p, collecting unknown members as extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members problemMembers
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	*p = Problem{
+		Type:     members.Type,
+		Title:    members.Title,
+		Status:   members.Status,
+		Detail:   members.Detail,
+		Instance: members.Instance,
+	}
+	for name, value := range all {
+		if isStandardMember(name) {
+			continue
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[name] = value
+	}
+	return nil
+}
+
+func isStandardMember(name string) bool {
+	switch name {
+	case "type", "title", "status", "detail", "instance":
+		return true
+	}
+	return false
+}
+
+// valueMember is the extension member holding an ErrorInfo
+// that is not encoded as a JSON object.
+const valueMember = "value"
+
+// problemType maps an ErrorInfo type to a problem type.
+type problemType struct {
+	uri string
+	// find returns the outermost info of this type attached to err.
+	find func(err error) (any, bool)
+	// attach decodes the info from ext and attaches it to err.
+	attach func(err error, ext map[string]any) (error, error)
+}
+
+var (
+	problemTypesMu sync.RWMutex
+	problemTypes   []problemType
+)
+
+// RegisterProblemType registers T as the ErrorInfo of the problems of type
+// uri. ToProblem gives errors carrying a T this type, with the T encoded as
+// extension members, and FromProblem attaches the T decoded from them to the
+// error it returns, so that GetErrorInfo[T] finds it on both sides.
+// T must be encodable to JSON. A T that is not encoded as a JSON object is
+// held by the "value" extension member.
+// RegisterProblemType panics if uri was already registered.
+func RegisterProblemType[T errors.ErrorInfo](uri string) {
+	problemTypesMu.Lock()
+	defer problemTypesMu.Unlock()
+	for _, pt := range problemTypes {
+		if pt.uri == uri {
+			panic(fmt.Sprintf("httperr: problem type %s registered twice", uri))
+		}
+	}
+	problemTypes = append(problemTypes, problemType{
+		uri: uri,
+		find: func(err error) (any, bool) {
+			return errors.GetErrorInfo[T](err)
+		},
+		attach: func(err error, ext map[string]any) (error, error) {
+			data, e := json.Marshal(ext)
+			if e != nil {
+				return nil, e
+			}
+			if value, ok := ext[valueMember]; ok && len(ext) == 1 {
+				if data, e = json.Marshal(value); e != nil {
+					return nil, e
+				}
+			}
+			var info T
+			if e := json.Unmarshal(data, &info); e != nil {
+				return nil, e
+			}
+			return errors.WithErrorInfo(err, info), nil
+		},
+	})
+}
+
+func lookupProblemType(uri string) (problemType, bool) {
+	problemTypesMu.RLock()
+	defer problemTypesMu.RUnlock()
+	for _, pt := range problemTypes {
+		if pt.uri == uri {
+			return pt, true
+		}
+	}
+	return problemType{}, false
+}
+
+// ToProblem returns the problem shown to clients for err:
+//
+//   - status is StatusOf(err) and title its status text
+//   - detail is the outermost PublicMessage of err, never the message of
+//     err itself, which may leak internal details
+//   - type is the one registered for the ErrorInfo attached to err, see
+//     RegisterProblemType, with that ErrorInfo as extension members;
+//     the first registered type wins if several are attached.
+//     Otherwise it is about:blank.
+//
+// When err carries a Problem, such as the errors of FromProblem, the
+// problem starts from it instead: its type, title, instance and extension
+// members are kept, and the title is only replaced when the status changed.
+func ToProblem(err error) Problem {
+	status := StatusOf(err)
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	received, isReceived := errors.GetErrorInfo[Problem](err)
+	if isReceived {
+		p.Type, p.Instance, p.Extensions = received.Type, received.Instance, received.Extensions
+		if received.Status == status && received.Title != "" {
+			p.Title = received.Title
+		}
+	}
+	if msg, ok := errors.GetErrorInfo[PublicMessage](err); ok {
+		p.Detail = string(msg)
+	}
+	if isReceived {
+		return p
+	}
+	problemTypesMu.RLock()
+	defer problemTypesMu.RUnlock()
+	for _, pt := range problemTypes {
+		info, ok := pt.find(err)
+		if !ok {
+			continue
+		}
+		if ext, ok := infoMembers(info); ok {
+			p.Type = pt.uri
+			p.Extensions = ext
+			break
+		}
+	}
+	return p
+}
+
+// infoMembers encodes info as extension members.
+func infoMembers(info any) (map[string]any, bool) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, false
+	}
+	if !bytes.HasPrefix(data, []byte("{")) {
+		return map[string]any{valueMember: json.RawMessage(data)}, true
+	}
+	var ext map[string]any
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return nil, false
+	}
+	return ext, true
+}
+
+// FromProblem returns an error for a problem received from another service.
+// Its message is the detail of p, or its title when there is none, and it
+// carries p as a Problem, its status as an HTTPStatus, its detail as a
+// PublicMessage and, when the type of p is registered, the ErrorInfo
+// decoded from its extension members, see RegisterProblemType. That
+// ErrorInfo is left out when the members cannot be decoded, the Problem
+// still holds them.
+func FromProblem(p Problem) error {
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	// the error comes from the caller, not from here
+	policy := errors.GetCapturePolicy()
+	policy.Skip++
+	err := errors.WithErrorInfo(policy.New(msg), p)
+	if p.Status != 0 {
+		err = WithStatus(err, p.Status)
+	}
+	if p.Detail != "" {
+		err = WithPublicMessage(err, p.Detail)
+	}
+	if pt, ok := lookupProblemType(p.Type); ok {
+		if decoded, e := pt.attach(err, p.Extensions); e == nil {
+			err = decoded
+		}
+	}
+	return err
+}
+
+// writeProblem writes p as an application/problem+json response.
+func writeProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
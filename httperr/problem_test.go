@@ -0,0 +1,165 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mochi-c/errors"
+)
+
+type outOfCredit struct {
+	Balance  int      `json:"balance"`
+	Accounts []string `json:"accounts"`
+}
+
+func (outOfCredit) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "out of credit"
+	}
+}
+
+type quotaExceeded int
+
+func (quotaExceeded) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "quota exceeded"
+	}
+}
+
+func init() {
+	RegisterProblemType[outOfCredit]("https://example.com/probs/out-of-credit")
+	RegisterProblemType[quotaExceeded]("https://example.com/probs/quota")
+}
+
+func TestProblemJSON(t *testing.T) {
+	p := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "Forbidden",
+		Status:     403,
+		Detail:     "Your current balance is 30",
+		Extensions: map[string]any{"balance": 30.0, "status": "ignored"},
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,"detail":"Your current balance is 30","balance":30}`
+	if string(data) != want {
+		t.Errorf("Marshal: got %s, want %s", data, want)
+	}
+
+	var got Problem
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	p.Extensions = map[string]any{"balance": 30.0}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("Unmarshal: got %+v, want %+v", got, p)
+	}
+}
+
+func TestToProblem(t *testing.T) {
+	err := errors.WithErrorInfo(errors.New("balance 30 < 50"), outOfCredit{Balance: 30, Accounts: []string{"a"}})
+	err = WithPublicMessage(WithStatus(err, http.StatusForbidden), "Your current balance is 30")
+
+	p := ToProblem(err)
+	want := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "Forbidden",
+		Status:     403,
+		Detail:     "Your current balance is 30",
+		Extensions: map[string]any{"balance": 30.0, "accounts": []any{"a"}},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+
+	p = ToProblem(errors.New("internal"))
+	if p.Type != "about:blank" || p.Status != 500 || p.Detail != "" || p.Extensions != nil {
+		t.Errorf("without info: got %+v", p)
+	}
+}
+
+func TestProblemRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		info any
+	}{
+		{"object", errors.WithErrorInfo(errors.New("no credit"), outOfCredit{Balance: 30, Accounts: []string{"a", "b"}}), outOfCredit{Balance: 30, Accounts: []string{"a", "b"}}},
+		{"value", WithStatus(errors.WithErrorInfo(errors.New("quota"), quotaExceeded(10)), 429), quotaExceeded(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(Handler(func(w http.ResponseWriter, r *http.Request) error {
+				return WithPublicMessage(tt.err, "try later")
+			}))
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			defer resp.Body.Close()
+			var p Problem
+			if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			got := FromProblem(p)
+			if got.Error() != "try later" || StatusOf(got) != resp.StatusCode {
+				t.Errorf("got %q with status %d", got.Error(), StatusOf(got))
+			}
+			var info any
+			switch tt.info.(type) {
+			case outOfCredit:
+				info, _ = errors.GetErrorInfo[outOfCredit](got)
+			case quotaExceeded:
+				info, _ = errors.GetErrorInfo[quotaExceeded](got)
+			}
+			if !reflect.DeepEqual(info, tt.info) {
+				t.Errorf("GetErrorInfo: got %#v, want %#v", info, tt.info)
+			}
+			if received, ok := errors.GetErrorInfo[Problem](got); !ok || received.Type != p.Type {
+				t.Errorf("GetErrorInfo[Problem]: got %+v %v", received, ok)
+			}
+		})
+	}
+}
+
+func TestFromProblemUnknownType(t *testing.T) {
+	err := FromProblem(Problem{Type: "https://example.com/unknown", Title: "Bad Request", Status: 400})
+	if err.Error() != "Bad Request" || StatusOf(err) != 400 {
+		t.Errorf("got %q with status %d", err.Error(), StatusOf(err))
+	}
+	if _, ok := errors.GetErrorInfo[PublicMessage](err); ok {
+		t.Errorf("got a PublicMessage without detail")
+	}
+}
+
+func TestProblemServedAgain(t *testing.T) {
+	p := Problem{
+		Type:       "https://example.com/unregistered",
+		Title:      "Teapot",
+		Status:     http.StatusTeapot,
+		Instance:   "/items/1",
+		Extensions: map[string]any{"a": float64(1)},
+	}
+	err := FromProblem(p)
+	if got := ToProblem(err); !reflect.DeepEqual(got, p) {
+		t.Errorf("ToProblem(FromProblem(p)): got %+v, want %+v", got, p)
+	}
+	if got := ToProblem(WithStatus(err, http.StatusBadGateway)); got.Status != http.StatusBadGateway || got.Title != "Bad Gateway" || got.Type != p.Type {
+		t.Errorf("ToProblem with another status: got %+v", got)
+	}
+	if frame, ok := errors.GetStackCause(err); !ok || frame.FuncName() != "TestProblemServedAgain" {
+		t.Errorf("GetStackCause: got %v %v, want TestProblemServedAgain", frame, ok)
+	}
+}
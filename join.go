@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// joinError joins several errors, each keeping its own stack,
+// and carries the stack of the place they were joined at.
+type joinError struct {
+	errs  []error
+	stack Stack
+}
+
+// Join returns an error that wraps the given errors, like errors.Join.
+// Any nil error values are discarded.
+// Join returns nil if every value in errs is nil.
+// The stack of the caller is recorded, and %+v renders every error
+// as a branch of a tree with its message and origin frame.
+func Join(errs ...error) error {
+	n := 0
+	for _, err := range errs {
+		if err != nil {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	e := &joinError{
+		errs:  make([]error, 0, n),
+		stack: callers(3, loadCapturePolicy()),
+	}
+	for _, err := range errs {
+		if err != nil {
+			e.errs = append(e.errs, err)
+		}
+	}
+	return e
+}
+
+func (e *joinError) GetStack() Stack {
+	return e.stack
+}
+
+func (e *joinError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap provides compatibility for Go 1.20 multi-error trees.
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+// Format formats the error according to the fmt.Formatter interface.
+//
+//	%s    the messages of every error, one per line, same as Error()
+//	%q    the double-quoted error message
+//	%v    equivalent to %s
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+v   a tree of every error with its message and origin frame,
+//	      followed by the stack of the join site
+func (e *joinError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatChain(s, e)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	goError "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJoinNil(t *testing.T) {
+	if err := Join(); err != nil {
+		t.Errorf("Join(): got %#v, expected nil", err)
+	}
+	if err := Join(nil, nil); err != nil {
+		t.Errorf("Join(nil, nil): got %#v, expected nil", err)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	first := WithMessage(io.EOF, "first")
+	second := New("second")
+	err := Join(first, nil, second)
+
+	if got, want := err.Error(), "first: EOF\nsecond"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+	if !goError.Is(err, io.EOF) {
+		t.Errorf("Is(err, io.EOF): got false")
+	}
+	branches := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(branches) != 2 || branches[0] != first || branches[1] != second {
+		t.Errorf("Unwrap(): got %v", branches)
+	}
+
+	frame, ok := GetStackCause(err)
+	if !ok || frame.FuncName() != "TestJoin" || frame.Line() != 23 {
+		t.Errorf("GetStackCause: got %v %v, want TestJoin:23", frame, ok)
+	}
+	stack, _ := GetStack(second)
+	if stack.StackSource().Line() != 22 {
+		t.Errorf("branch stack: got line %d, want 22", stack.StackSource().Line())
+	}
+}
+
+func TestJoinFormat(t *testing.T) {
+	inner := Join(New("a"), goError.New("b"))
+	err := WithMessage(Join(WithMessage(io.EOF, "first"), inner), "msg")
+
+	if got, want := fmt.Sprintf("%v", err), "msg: first: EOF\na\nb"; got != want {
+		t.Errorf("Sprintf(%%v): got %q, want %q", got, want)
+	}
+
+	got := fmt.Sprintf("%+v", err)
+	wantLines := []string{
+		"msg",
+		"  at github.com/mochi-c/errors.TestJoinFormat (",
+		"2 errors",
+		"- first: EOF",
+		"  at github.com/mochi-c/errors.TestJoinFormat (",
+		"- 2 errors",
+		"  at github.com/mochi-c/errors.TestJoinFormat (",
+		"  - a",
+		"    at github.com/mochi-c/errors.TestJoinFormat (",
+		"  - b",
+		"github.com/mochi-c/errors.TestJoinFormat",
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) < len(wantLines) {
+		t.Fatalf("Sprintf(%%+v): got %q", got)
+	}
+	for i, want := range wantLines {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Sprintf(%%+v) line %d: got %q, want prefix %q", i, lines[i], want)
+		}
+	}
+}
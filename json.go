@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type jsonError struct {
+	Message string      `json:"message"`
+	Layers  []jsonLayer `json:"layers"`
+	Stack   []jsonFrame `json:"stack,omitempty"`
+}
+
+// jsonLayer is either a layer added by WithErrorInfo and its helpers,
+// with the Go type of its ErrorInfo, or a foreign error with its own type.
+type jsonLayer struct {
+	Text string          `json:"text,omitempty"`
+	Type string          `json:"type"`
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+type jsonFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// ToJSON encodes err as a JSON object holding its message, every error of
+// its tree in the order GetAllErrorInfo walks them, and the deepest stack:
+//
+//	{
+//		"message": "msg: whoops",
+//		"layers": [
+//			{"text": "msg", "type": "errors.message", "info": "msg"},
+//			{"type": "errors.emptyInfo", "info": {}},
+//			{"text": "whoops", "type": "*errors.errorString"}
+//		],
+//		"stack": [{"func": "main.main", "file": "/src/main.go", "line": 12}]
+//	}
+//
+// The info of a layer is only present when it can be marshalled to JSON.
+// ToJSON works for any error, foreign ones are listed as a single layer.
+// If err is nil, ToJSON returns null.
+func ToJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(newJSONError(err))
+}
+
+func newJSONError(err error) jsonError {
+	res := jsonError{
+		Message: err.Error(),
+		Layers:  make([]jsonLayer, 0),
+	}
+	walk(err, func(err error) bool {
+		res.Layers = append(res.Layers, newJSONLayer(err))
+		return true
+	})
+	if stack, ok := GetStack(err); ok {
+		res.Stack = newJSONStack(stack)
+	}
+	return res
+}
+
+func newJSONLayer(err error) jsonLayer {
+	l, ok := err.(layer)
+	if !ok {
+		return jsonLayer{
+			Text: err.Error(),
+			Type: fmt.Sprintf("%T", err),
+		}
+	}
+	info := l.layerInfo()
+	res := jsonLayer{
+		Text: l.layerText(),
+		Type: fmt.Sprintf("%T", info),
+	}
+	if raw, err := json.Marshal(info); err == nil {
+		res.Info = raw
+	}
+	return res
+}
+
+func newJSONStack(stack Stack) []jsonFrame {
+	frames := stack.StackTrace()
+	res := make([]jsonFrame, len(frames))
+	for i, frame := range frames {
+		res[i] = jsonFrame{
+			Func: frame.FullFuncName(),
+			File: frame.File(),
+			Line: frame.Line(),
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"encoding/json"
+	goError "errors"
+	"reflect"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	err := WithErrorInfo(goError.New("whoops"), CodeInfo{Code: 100})
+	err = WithMessage(err, "msg")
+
+	data, e := json.Marshal(err)
+	if e != nil {
+		t.Fatalf("Marshal: %v", e)
+	}
+	var got jsonError
+	if e := json.Unmarshal(data, &got); e != nil {
+		t.Fatalf("Unmarshal %s: %v", data, e)
+	}
+
+	if got.Message != "msg: whoops" {
+		t.Errorf("message: got %q", got.Message)
+	}
+	wantLayers := []jsonLayer{
+		{Text: "msg", Type: "errors.message", Info: json.RawMessage(`"msg"`)},
+		{Text: "100", Type: "errors.CodeInfo", Info: json.RawMessage(`{"Code":100}`)},
+		{Text: "whoops", Type: "*errors.errorString"},
+	}
+	if !reflect.DeepEqual(got.Layers, wantLayers) {
+		t.Errorf("layers: got %+v, want %+v", got.Layers, wantLayers)
+	}
+	if len(got.Stack) == 0 || got.Stack[0].Func != "github.com/mochi-c/errors.TestToJSON" || got.Stack[0].Line != 11 {
+		t.Errorf("stack: got %+v", got.Stack)
+	}
+}
+
+type funcInfo func()
+
+func (funcInfo) WhenError(cause error) string { return "func" }
+
+func TestToJSONForeign(t *testing.T) {
+	data, err := ToJSON(goError.New("whoops"))
+	if err != nil || string(data) != `{"message":"whoops","layers":[{"text":"whoops","type":"*errors.errorString"}]}` {
+		t.Errorf("ToJSON: got %s %v", data, err)
+	}
+
+	data, err = ToJSON(nil)
+	if err != nil || string(data) != "null" {
+		t.Errorf("ToJSON(nil): got %s %v", data, err)
+	}
+
+	data, err = ToJSON(WithErrorInfo(goError.New("whoops"), funcInfo(nil)))
+	if err != nil {
+		t.Fatalf("ToJSON with unmarshallable info: %v", err)
+	}
+	var got jsonError
+	if err := json.Unmarshal(data, &got); err != nil || got.Layers[0].Info != nil {
+		t.Errorf("ToJSON with unmarshallable info: got %s", data)
+	}
+}
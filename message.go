@@ -18,7 +18,7 @@ func WithMessage(err error, msg string) error {
 	if err == nil {
 		return nil
 	}
-	return withErrorInfo(err, message(msg))
+	return withErrorInfo(err, message(msg), loadCapturePolicy())
 }
 
 // WithMessagef annotates err with the format specifier.
@@ -28,8 +28,8 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 		return nil
 	}
 	if len(args) > 0 {
-		return withErrorInfo(err, message(fmt.Sprintf(format, args...)))
+		return withErrorInfo(err, message(fmt.Sprintf(format, args...)), loadCapturePolicy())
 	} else {
-		return withErrorInfo(err, message(format))
+		return withErrorInfo(err, message(format), loadCapturePolicy())
 	}
 }
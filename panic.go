@@ -0,0 +1,50 @@
+package errors
+
+import "fmt"
+
+// PanicInfo is the ErrorInfo of the errors built by Recover and Try.
+type PanicInfo struct {
+	// Value is the value passed to panic.
+	Value any
+}
+
+func (info PanicInfo) WhenError(cause error) string {
+	if cause != nil {
+		return "panic: " + cause.Error()
+	} else {
+		return fmt.Sprintf("panic: %v", info.Value)
+	}
+}
+
+// Recover converts a panic into an error stored in *errp, to be deferred
+// by functions returning an error:
+//
+//	func work() (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+//
+// The error carries the stack of the panicking goroutine starting at the
+// panic site, and a PanicInfo holding the recovered value. When that value is
+// an error, it is the cause of the returned error, so errors.Is and errors.As
+// find it. Recover does nothing when the goroutine is not panicking.
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = newPanicError(r, panicCallers(3, loadCapturePolicy()))
+	}
+}
+
+// Try calls fn and returns its error, or the error built by Recover if fn panics.
+func Try(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+func newPanicError(r any, stack Stack) error {
+	cause, _ := r.(error)
+	return &fundamental[PanicInfo]{
+		cause: cause,
+		info:  PanicInfo{Value: r},
+		stack: stack,
+	}
+}
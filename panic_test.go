@@ -0,0 +1,84 @@
+package errors
+
+import (
+	goError "errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func panicWith(v any) (err error) {
+	defer Recover(&err)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	err := panicWith("boom")
+
+	if err.Error() != "panic: boom" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	if info, ok := GetErrorInfo[PanicInfo](err); !ok || info.Value != "boom" {
+		t.Errorf("GetErrorInfo: got %v %v", info, ok)
+	}
+	frame, ok := GetStackCause(err)
+	if !ok || frame.FuncName() != "panicWith" || frame.Line() != 12 {
+		t.Errorf("GetStackCause: got %v %v, want panicWith:12", frame, ok)
+	}
+	stack, _ := GetStack(err)
+	if frames := stack.StackTrace(); len(frames) < 2 || frames[1].FuncName() != "TestRecover" {
+		t.Errorf("stack: got %v", frames)
+	}
+}
+
+func TestRecoverError(t *testing.T) {
+	err := panicWith(customErr{msg: "custom"})
+
+	if err.Error() != "panic: custom" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	var target customErr
+	if !goError.As(err, &target) || target.msg != "custom" {
+		t.Errorf("As: got %v", target)
+	}
+	if !goError.Is(panicWith(io.EOF), io.EOF) {
+		t.Errorf("Is: got false")
+	}
+}
+
+func TestTry(t *testing.T) {
+	if err := Try(func() error { return nil }); err != nil {
+		t.Errorf("Try without error: got %v", err)
+	}
+	if err := Try(func() error { return io.EOF }); err != io.EOF {
+		t.Errorf("Try with error: got %v", err)
+	}
+
+	err := Try(func() error {
+		var m map[string]int
+		m["key"] = 1
+		return nil
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: assignment to entry in nil map") {
+		t.Fatalf("Try with runtime panic: got %v", err)
+	}
+	frame, ok := GetStackCause(err)
+	if !ok || frame.FuncName() != "TestTry.func3" || frame.Line() != 59 {
+		t.Errorf("GetStackCause: got %s:%d %v, want TestTry.func3:59", frame.FuncName(), frame.Line(), ok)
+	}
+}
+
+func TestRecoverCapturePolicy(t *testing.T) {
+	defer SetCapturePolicy(GetCapturePolicy())
+
+	SetCapturePolicy(CapturePolicy{Mode: CaptureOrigin})
+	stack, _ := GetStack(panicWith("boom"))
+	if frames := stack.StackTrace(); len(frames) != 1 || frames[0].FuncName() != "panicWith" {
+		t.Errorf("origin: got %v", frames)
+	}
+
+	SetCapturePolicy(CapturePolicy{Mode: CaptureDisabled})
+	if _, ok := GetStack(panicWith("boom")); ok {
+		t.Errorf("disabled: found a stack")
+	}
+}
@@ -0,0 +1,99 @@
+// Package pkgcompat provides the API of github.com/pkg/errors on top of
+// github.com/mochi-c/errors, so code using pkg/errors can migrate by
+// changing its import path alone.
+//
+// Its errors are those of the errors package: they carry a single stack,
+// captured following errors.GetCapturePolicy, and satisfy the
+// interface{ StackTrace() StackTrace } interface pkg/errors users look for.
+package pkgcompat
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/mochi-c/errors"
+)
+
+// StackTrace is the stack of an error, innermost frame first.
+type StackTrace = errors.StackTrace
+
+// Frame is a frame of a StackTrace.
+type Frame = errors.Frame
+
+// capture returns the package policy, skipping the frame of the function of
+// this package calling it.
+func capture() errors.CapturePolicy {
+	policy := errors.GetCapturePolicy()
+	policy.Skip++
+	return policy
+}
+
+// New returns an error with the supplied message and the stack of its caller.
+func New(message string) error {
+	return capture().New(message)
+}
+
+// Errorf formats according to a format specifier and returns the string as
+// an error with the stack of its caller.
+func Errorf(format string, args ...interface{}) error {
+	return capture().Errorf(format, args...)
+}
+
+// WithStack annotates err with the stack of its caller, unless err already
+// has one. If err is nil, WithStack returns nil.
+func WithStack(err error) error {
+	return capture().Wrap(err)
+}
+
+// Wrap annotates err with the supplied message and the stack of its caller,
+// unless err already has one. If err is nil, Wrap returns nil.
+func Wrap(err error, message string) error {
+	return capture().WithMessage(err, message)
+}
+
+// Wrapf annotates err with the format specifier and the stack of its caller,
+// unless err already has one. If err is nil, Wrapf returns nil.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return capture().WithMessage(err, fmt.Sprintf(format, args...))
+}
+
+// WithMessage annotates err with a new message.
+// If err is nil, WithMessage returns nil.
+func WithMessage(err error, message string) error {
+	return capture().WithMessage(err, message)
+}
+
+// WithMessagef annotates err with the format specifier.
+// If err is nil, WithMessagef returns nil.
+func WithMessagef(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return capture().WithMessage(err, fmt.Sprintf(format, args...))
+}
+
+// Cause returns the underlying cause of the error, see errors.Cause.
+func Cause(err error) error {
+	return errors.Cause(err)
+}
+
+// Is reports whether any error in err's chain matches target, see the
+// standard errors.Is.
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, see the
+// standard errors.As.
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, see the
+// standard errors.Unwrap.
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
+}
@@ -0,0 +1,69 @@
+package pkgcompat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type stackTracer interface {
+	StackTrace() StackTrace
+}
+
+func TestStackTracer(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		msg  string
+	}{
+		{"New", New("whoops"), "whoops"},
+		{"Errorf", Errorf("whoops %d", 1), "whoops 1"},
+		{"WithStack", WithStack(io.EOF), "EOF"},
+		{"Wrap", Wrap(io.EOF, "read"), "read: EOF"},
+		{"Wrapf", Wrapf(io.EOF, "read %s", "file"), "read file: EOF"},
+		{"WithMessagef", WithMessagef(io.EOF, "100%%"), "100%: EOF"},
+	}
+	for _, tt := range tests {
+		if tt.err.Error() != tt.msg {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.err.Error(), tt.msg)
+		}
+		st, ok := tt.err.(stackTracer)
+		if !ok {
+			t.Fatalf("%s: %T is not a stackTracer", tt.name, tt.err)
+		}
+		frames := st.StackTrace()
+		if len(frames) == 0 || frames[0].FuncName() != "TestStackTracer" {
+			t.Errorf("%s: got %v", tt.name, frames)
+		}
+	}
+}
+
+func TestNil(t *testing.T) {
+	if WithStack(nil) != nil || Wrap(nil, "msg") != nil || Wrapf(nil, "msg %d", 1) != nil ||
+		WithMessage(nil, "msg") != nil || WithMessagef(nil, "msg %d", 1) != nil {
+		t.Error("nil error wrapped")
+	}
+}
+
+func TestStackTraceFormat(t *testing.T) {
+	st := New("whoops").(stackTracer).StackTrace()
+
+	if got := fmt.Sprintf("%v", st[:1]); got != "[pkgcompat_test.go:50]" {
+		t.Errorf("%%v: got %q", got)
+	}
+	if got := fmt.Sprintf("%+v", st[:1]); !strings.HasPrefix(got, "\ngithub.com/mochi-c/errors/pkgcompat.TestStackTraceFormat\n\t") ||
+		!strings.HasSuffix(got, "pkgcompat_test.go:50") {
+		t.Errorf("%%+v: got %q", got)
+	}
+}
+
+func TestCause(t *testing.T) {
+	err := Wrap(WithMessage(io.EOF, "msg"), "wrap")
+	if Cause(err) != io.EOF || !Is(err, io.EOF) {
+		t.Errorf("Cause: got %v", Cause(err))
+	}
+	if Unwrap(Unwrap(err)) != io.EOF {
+		t.Errorf("Unwrap: got %v", Unwrap(Unwrap(err)))
+	}
+}
@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RemoteFrame is a frame symbolized by another process, see DecodeJSON.
+// Unlike the frames of local stacks it has no program counter.
+type RemoteFrame struct {
+	Func string `json:"func"`
+	Path string `json:"file"`
+	Num  int    `json:"line"`
+}
+
+func (f RemoteFrame) File() string { return f.Path }
+
+func (f RemoteFrame) Line() int { return f.Num }
+
+func (f RemoteFrame) FullFuncName() string { return f.Func }
+
+// FuncName removes the path prefix component of a function's Name.
+func (f RemoteFrame) FuncName() string {
+	name := f.Func
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+	i = strings.Index(name, ".")
+	return name[i+1:]
+}
+
+// Format formats the frame like the frames of local stacks.
+func (f RemoteFrame) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		switch {
+		case s.Flag('+'):
+			io.WriteString(s, f.FullFuncName())
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, f.File())
+		default:
+			io.WriteString(s, path.Base(f.File()))
+		}
+	case 'd':
+		io.WriteString(s, strconv.Itoa(f.Line()))
+	case 'n':
+		io.WriteString(s, f.FuncName())
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// RemoteStack is a Stack decoded from another process, its frames are
+// foreign to the current one.
+type RemoteStack struct {
+	Frames []RemoteFrame
+}
+
+func (stack *RemoteStack) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		for _, f := range stack.Frames {
+			fmt.Fprintf(st, "\n%+v", f)
+		}
+		fmt.Fprintf(st, "\n")
+	}
+}
+
+func (stack *RemoteStack) StackTrace() []Frame {
+	f := make([]Frame, len(stack.Frames))
+	for i, frame := range stack.Frames {
+		f[i] = frame
+	}
+	return f
+}
+
+func (stack *RemoteStack) StackSource() Frame {
+	if len(stack.Frames) == 0 {
+		return RemoteFrame{Func: "unknown", Path: "unknown"}
+	}
+	return stack.Frames[0]
+}
+
+// remoteInfo stands for an ErrorInfo whose type is not registered in
+// the current process, see RegisterInfoType.
+type remoteInfo struct {
+	// Type is the name of the ErrorInfo type in the sender.
+	Type string
+	// Text is the message of the layer in the sender.
+	Text string
+	// Message is the message of the layer without its cause.
+	Message string
+}
+
+func (info remoteInfo) WhenError(cause error) string {
+	if cause == nil {
+		return info.Message
+	}
+	return info.Text
+}
+
+// remoteError stands for a foreign error of another process.
+type remoteError struct {
+	typ   string
+	text  string
+	cause error
+	stack Stack
+}
+
+func (e *remoteError) Error() string {
+	return e.text
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.cause
+}
+
+func (e *remoteError) GetStack() Stack {
+	return e.stack
+}
+
+// remoteJoinError stands for a foreign error joining several errors
+// in another process.
+type remoteJoinError struct {
+	typ   string
+	text  string
+	errs  []error
+	stack Stack
+}
+
+func (e *remoteJoinError) Error() string {
+	return e.text
+}
+
+func (e *remoteJoinError) Unwrap() []error {
+	return e.errs
+}
+
+func (e *remoteJoinError) GetStack() Stack {
+	return e.stack
+}
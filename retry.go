@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Retryable is the ErrorInfo marking an error as worth retrying,
+// see IsRetryable.
+type Retryable struct {
+	// After is how long to wait before retrying, 0 when unknown.
+	After time.Duration
+}
+
+func (info Retryable) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else if info.After > 0 {
+		return "retryable after " + info.After.String()
+	} else {
+		return "retryable"
+	}
+}
+
+// WithRetryable marks err as worth retrying.
+// If err is nil, WithRetryable returns nil.
+func WithRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, Retryable{}, loadCapturePolicy())
+}
+
+// WithRetryAfter marks err as worth retrying after d.
+// If err is nil, WithRetryAfter returns nil.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, Retryable{After: d}, loadCapturePolicy())
+}
+
+// IsRetryable reports whether err is worth retrying: when a Retryable is
+// attached to it, when an error of its tree reports true from a
+// Temporary() bool or Timeout() bool method, as net.Error does, or when it
+// is a context.DeadlineExceeded.
+func IsRetryable(err error) bool {
+	if _, ok := GetErrorInfo[Retryable](err); ok {
+		return true
+	}
+	retryable := false
+	walk(err, func(err error) bool {
+		if t, ok := err.(interface{ Temporary() bool }); ok && t.Temporary() {
+			retryable = true
+		} else if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			retryable = true
+		} else if err == context.DeadlineExceeded {
+			retryable = true
+		}
+		return !retryable
+	})
+	return retryable
+}
+
+// RetryAfter returns the outermost delay hint of the Retryable attached to err.
+func RetryAfter(err error) (time.Duration, bool) {
+	info, ok := GetErrorInfo[Retryable](err)
+	if !ok || info.After <= 0 {
+		return 0, false
+	}
+	return info.After, true
+}
+
+// RetryInfo is the ErrorInfo attached by Retry to the error it returns.
+type RetryInfo struct {
+	// Attempts is the number of times the function was called.
+	Attempts int
+	// Causes holds the error of every attempt, the first one first.
+	Causes []error
+}
+
+func (info RetryInfo) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return fmt.Sprintf("failed after %d attempts", info.Attempts)
+	}
+}
+
+// MarshalJSON encodes the errors of the attempts by their messages.
+func (info RetryInfo) MarshalJSON() ([]byte, error) {
+	causes := make([]string, len(info.Causes))
+	for i, cause := range info.Causes {
+		causes[i] = cause.Error()
+	}
+	return json.Marshal(struct {
+		Attempts int      `json:"attempts"`
+		Causes   []string `json:"causes"`
+	}{info.Attempts, causes})
+}
+
+// RetryPolicy tells Retry how many times to call a function and how long
+// to wait in between.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, 3 when unset.
+	MaxAttempts int
+	// Backoff is the wait after the first failed attempt, doubled after
+	// every other one. A longer RetryAfter hint of the error wins.
+	Backoff time.Duration
+	// MaxBackoff caps the wait computed from Backoff when set.
+	MaxBackoff time.Duration
+}
+
+// Retry calls fn until it succeeds, returns an error IsRetryable rejects,
+// policy.MaxAttempts is reached or ctx is done. The returned error is the one
+// of the last attempt with a RetryInfo listing the errors of every attempt.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	backoff := policy.Backoff
+	var causes []error
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		causes = append(causes, err)
+		if len(causes) >= maxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return withRetryInfo(err, causes)
+		}
+
+		wait := backoff
+		if after, ok := RetryAfter(err); ok && after > wait {
+			wait = after
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return withRetryInfo(err, causes)
+			case <-timer.C:
+			}
+		}
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+func withRetryInfo(err error, causes []error) error {
+	info := RetryInfo{Attempts: len(causes), Causes: causes}
+	return withErrorInfo(err, info, loadCapturePolicy())
+}
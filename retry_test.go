@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"context"
+	goError "errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return false }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", New("whoops"), false},
+		{"retryable", WithMessage(WithRetryable(io.EOF), "msg"), true},
+		{"timeout", fmt.Errorf("dial: %w", timeoutErr{}), true},
+		{"net", &net.OpError{Op: "dial", Err: timeoutErr{}}, true},
+		{"deadline", Wrap(context.DeadlineExceeded), true},
+		{"canceled", Wrap(context.Canceled), false},
+		{"joined", Join(io.EOF, WithRetryable(io.EOF)), true},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if after, ok := RetryAfter(WithRetryAfter(io.EOF, time.Second)); !ok || after != time.Second {
+		t.Errorf("RetryAfter: got %v %v", after, ok)
+	}
+	if _, ok := RetryAfter(WithRetryable(io.EOF)); ok {
+		t.Errorf("RetryAfter without hint: got true")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 5}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return WithRetryable(fmt.Errorf("attempt %d", calls))
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), RetryPolicy{Backoff: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return WithRetryable(fmt.Errorf("attempt %d", calls))
+	})
+	info, ok := GetErrorInfo[RetryInfo](err)
+	if !ok || info.Attempts != 3 || len(info.Causes) != 3 || info.Causes[0].Error() != "attempt 1" {
+		t.Errorf("RetryInfo: got %+v %v", info, ok)
+	}
+	if err.Error() != "attempt 3" || !strings.Contains(fmt.Sprintf("%+v", err), "failed after 3 attempts") {
+		t.Errorf("got %q", fmt.Sprintf("%+v", err))
+	}
+
+	calls = 0
+	err = Retry(context.Background(), RetryPolicy{}, func(ctx context.Context) error {
+		calls++
+		return io.EOF
+	})
+	if calls != 1 || !goError.Is(err, io.EOF) {
+		t.Errorf("not retryable: got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 10}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return WithRetryAfter(io.EOF, time.Hour)
+	})
+	if calls != 1 || !goError.Is(err, io.EOF) {
+		t.Errorf("got %v after %d calls", err, calls)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	calls = 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err = Retry(ctx, RetryPolicy{MaxAttempts: 10}, func(ctx context.Context) error {
+		calls++
+		return WithRetryAfter(io.EOF, time.Hour)
+	})
+	if info, _ := GetErrorInfo[RetryInfo](err); calls != 1 || info.Attempts != 1 {
+		t.Errorf("canceled while waiting: got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+)
+
+// Sentinel is an error meant to be declared once and returned many times:
+//
+//	var ErrNotFound = errors.NewSentinel("not found")
+//
+// Returning ErrNotFound.Throw() or ErrNotFound.Wrap(cause) instead of
+// ErrNotFound gives an error carrying the stack of where it is returned,
+// while errors.Is(err, ErrNotFound) still matches.
+// Unlike var ErrNotFound = New("not found"), no stack is captured at init.
+// Sentinels are matched by identity: two sentinels with the same message
+// do not match each other.
+type Sentinel struct {
+	msg string
+	// pkg is the path of the package declaring the sentinel.
+	pkg string
+	// decoded is set for the sentinels of errors rebuilt by DecodeJSON and
+	// DecodeBinary, which match the sentinel of the same package and message.
+	decoded bool
+}
+
+// NewSentinel returns a new Sentinel with the message msg.
+func NewSentinel(msg string) *Sentinel {
+	s := &Sentinel{msg: msg}
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			s.pkg = funcPackage(fn.Name())
+		}
+	}
+	return s
+}
+
+// funcPackage returns the package path of a function name, such as
+// "github.com/a/b" for "github.com/a/b.init".
+func funcPackage(name string) string {
+	dir, base := "", name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		dir, base = name[:i+1], name[i+1:]
+	}
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	return dir + base
+}
+
+func (s *Sentinel) Error() string {
+	return s.msg
+}
+
+// WhenError prefixes the message of cause with s.
+func (s *Sentinel) WhenError(cause error) string {
+	if cause == nil {
+		return s.msg
+	} else {
+		return s.msg + ": " + cause.Error()
+	}
+}
+
+// Throw returns a new error matching s with the current stack.
+func (s *Sentinel) Throw() error {
+	return newFundamental(s)
+}
+
+// Wrap annotates cause with s: the returned error matches both s and cause.
+// It carries the stack of cause, or the current one if cause has none.
+// If cause is nil, Wrap returns nil.
+func (s *Sentinel) Wrap(cause error) error {
+	if cause == nil {
+		return nil
+	}
+	return withErrorInfo(cause, s, loadCapturePolicy())
+}
+
+// is reports whether target is s, or the sentinel s was decoded from.
+func (s *Sentinel) is(target error) bool {
+	t, ok := target.(*Sentinel)
+	if !ok {
+		return false
+	}
+	return s == t || s.decoded && !t.decoded && s.pkg == t.pkg && s.msg == t.msg
+}
+
+type jsonSentinel struct {
+	Msg string `json:"msg"`
+	Pkg string `json:"pkg,omitempty"`
+}
+
+func (s *Sentinel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSentinel{Msg: s.msg, Pkg: s.pkg})
+}
+
+func (s *Sentinel) UnmarshalJSON(data []byte) error {
+	var j jsonSentinel
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = Sentinel{msg: j.Msg, pkg: j.Pkg, decoded: true}
+	return nil
+}
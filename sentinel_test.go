@@ -0,0 +1,73 @@
+package errors
+
+import (
+	goError "errors"
+	"io"
+	"testing"
+)
+
+var (
+	errTestNotFound = NewSentinel("not found")
+	errTestOther    = NewSentinel("other")
+)
+
+func TestSentinelThrow(t *testing.T) {
+	err := errTestNotFound.Throw()
+
+	if err.Error() != "not found" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	if !goError.Is(err, errTestNotFound) || !goError.Is(WithMessage(err, "msg"), errTestNotFound) {
+		t.Errorf("Is: got false")
+	}
+	if goError.Is(err, errTestOther) || goError.Is(err, goError.New("not found")) {
+		t.Errorf("Is: matched another error")
+	}
+	frame, ok := GetStackCause(err)
+	if !ok || frame.FuncName() != "TestSentinelThrow" || frame.Line() != 15 {
+		t.Errorf("GetStackCause: got %v %v, want TestSentinelThrow:15", frame, ok)
+	}
+	if Cause(err) != err {
+		t.Errorf("Cause: got %v", Cause(err))
+	}
+}
+
+func TestSentinelWrap(t *testing.T) {
+	if got := errTestNotFound.Wrap(nil); got != nil {
+		t.Errorf("Wrap(nil): got %#v, expected nil", got)
+	}
+
+	err := errTestNotFound.Wrap(io.EOF)
+	if err.Error() != "not found: EOF" {
+		t.Errorf("Error(): got %q", err.Error())
+	}
+	if !goError.Is(err, errTestNotFound) || !goError.Is(err, io.EOF) {
+		t.Errorf("Is: got false")
+	}
+	if frame, ok := GetStackCause(err); !ok || frame.Line() != 40 {
+		t.Errorf("GetStackCause: got %v %v, want line 40", frame, ok)
+	}
+	if s, ok := GetErrorInfo[*Sentinel](err); !ok || s != errTestNotFound {
+		t.Errorf("GetErrorInfo: got %v %v", s, ok)
+	}
+}
+
+func TestSentinelIdentity(t *testing.T) {
+	// the same message, declared in another package
+	same := &Sentinel{msg: "not found", pkg: "example.com/other"}
+	if goError.Is(errTestNotFound.Throw(), same) || goError.Is(same.Throw(), errTestNotFound) {
+		t.Errorf("Is: matched another sentinel with the same message")
+	}
+	if errTestNotFound.pkg != "github.com/mochi-c/errors" {
+		t.Errorf("pkg: got %q", errTestNotFound.pkg)
+	}
+
+	data, _ := EncodeJSON(errTestNotFound.Throw())
+	got, err := DecodeJSON(data)
+	if err != nil {
+		t.Fatalf("DecodeJSON: %v", err)
+	}
+	if !goError.Is(got, errTestNotFound) || goError.Is(got, same) {
+		t.Errorf("Is: a decoded sentinel does not match the sentinel it was sent from only")
+	}
+}
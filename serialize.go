@@ -0,0 +1,279 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// infoType decodes the ErrorInfo values of a registered type.
+type infoType struct {
+	// wrap decodes an info from data and returns a layer with it.
+	wrap func(data []byte, cause error, stack Stack) (error, error)
+}
+
+var (
+	infoTypesMu sync.RWMutex
+	infoTypes   = make(map[string]infoType)
+)
+
+func init() {
+	RegisterInfoType[emptyInfo]()
+	RegisterInfoType[message]()
+	RegisterInfoType[Fields]()
+	RegisterInfoType[Code]()
+	RegisterInfoType[*Sentinel]()
+	RegisterInfoType[PanicInfo]()
+	RegisterInfoType[Retryable]()
+	RegisterInfoType[Severity]()
+}
+
+// RegisterInfoType registers T so that DecodeJSON and DecodeBinary rebuild
+// the layers carrying a T, found again by GetErrorInfo[T]. T must be encodable
+// to JSON and both processes must register it. The layers of types not
+// registered are decoded with their message only.
+// The ErrorInfo types of the package are registered already.
+// RegisterInfoType panics if T was already registered.
+func RegisterInfoType[T ErrorInfo]() {
+	name := infoTypeName(reflect.TypeOf((*T)(nil)).Elem())
+	infoTypesMu.Lock()
+	defer infoTypesMu.Unlock()
+	if _, ok := infoTypes[name]; ok {
+		panic(fmt.Sprintf("errors: info type %s registered twice", name))
+	}
+	infoTypes[name] = infoType{
+		wrap: func(data []byte, cause error, stack Stack) (error, error) {
+			var info T
+			if err := json.Unmarshal(data, &info); err != nil {
+				return nil, err
+			}
+			return &fundamental[T]{
+				cause: cause,
+				info:  info,
+				stack: stack,
+			}, nil
+		},
+	}
+}
+
+func lookupInfoType(name string) (infoType, bool) {
+	infoTypesMu.RLock()
+	defer infoTypesMu.RUnlock()
+	t, ok := infoTypes[name]
+	return t, ok
+}
+
+// infoTypeName returns the name a type is registered with, made of its
+// package path and name, like "*github.com/mochi-c/errors.PanicInfo".
+func infoTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + infoTypeName(t.Elem())
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// wireError is what is sent by EncodeJSON and EncodeBinary.
+type wireError struct {
+	// Stacks holds every distinct stack of the chain, referred by its nodes.
+	Stacks [][]RemoteFrame `json:"stacks,omitempty"`
+	Chain  []wireNode      `json:"chain"`
+}
+
+// wireNode is an error of the chain, outermost first.
+type wireNode struct {
+	// Type is the registered name of the ErrorInfo of a layer,
+	// or the Go type of a foreign error.
+	Type  string `json:"type"`
+	Layer bool   `json:"layer,omitempty"`
+	// Info is the ErrorInfo of a layer whose type is registered.
+	Info json.RawMessage `json:"info,omitempty"`
+	// Text is the message of the error.
+	Text string `json:"text"`
+	// Message is the message of a layer without its cause, the text of
+	// its ErrorInfo for a nil cause.
+	Message string `json:"message,omitempty"`
+	// Stack is the index of the stack of the error in Stacks plus one,
+	// 0 when it has none.
+	Stack int `json:"stack,omitempty"`
+	// Branches holds the chains of an error joining several errors.
+	Branches [][]wireNode `json:"branches,omitempty"`
+}
+
+type wireEncoder struct {
+	wire   wireError
+	stacks map[Stack]int
+}
+
+func newWireError(err error) wireError {
+	e := &wireEncoder{stacks: make(map[Stack]int)}
+	e.wire.Chain = e.chain(err)
+	return e.wire
+}
+
+func (e *wireEncoder) chain(err error) []wireNode {
+	nodes := make([]wireNode, 0)
+	for err != nil {
+		node := wireNode{
+			Type: fmt.Sprintf("%T", err),
+			Text: err.Error(),
+		}
+		if l, ok := err.(layer); ok {
+			info := l.layerInfo()
+			node.Type = infoTypeName(reflect.TypeOf(info))
+			node.Layer = true
+			node.Message = l.layerText()
+			if _, ok := lookupInfoType(node.Type); ok {
+				if data, err := json.Marshal(info); err == nil {
+					node.Info = data
+				}
+			}
+		}
+		if ins, ok := err.(HasStack); ok {
+			node.Stack = e.stack(ins.GetStack())
+		}
+		switch cause := err.(type) {
+		case unwraper:
+			err = cause.Unwrap()
+		case multiUnwraper:
+			for _, branch := range cause.Unwrap() {
+				if branch != nil {
+					node.Branches = append(node.Branches, e.chain(branch))
+				}
+			}
+			err = nil
+		default:
+			err = nil
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// stack returns the reference to stack in the stacks of the wire error,
+// adding it the first time it is seen.
+func (e *wireEncoder) stack(stack Stack) int {
+	if stack == nil {
+		return 0
+	}
+	comparable := reflect.TypeOf(stack).Comparable()
+	if comparable {
+		if ref, ok := e.stacks[stack]; ok {
+			return ref
+		}
+	}
+	frames := stack.StackTrace()
+	remote := make([]RemoteFrame, len(frames))
+	for i, frame := range frames {
+		remote[i] = RemoteFrame{
+			Func: frame.FullFuncName(),
+			Path: frame.File(),
+			Num:  frame.Line(),
+		}
+	}
+	e.wire.Stacks = append(e.wire.Stacks, remote)
+	ref := len(e.wire.Stacks)
+	if comparable {
+		e.stacks[stack] = ref
+	}
+	return ref
+}
+
+// maxWireDepth bounds the nesting of the branches of a decoded error, so a
+// crafted message cannot exhaust the stack of the receiver, like the
+// nesting limit of encoding/json.
+const maxWireDepth = 10000
+
+var errWireTooDeep = fmt.Errorf("errors: decode: exceeded max depth of %d", maxWireDepth)
+
+type wireDecoder struct {
+	stacks []Stack
+	depth  int
+}
+
+func decodeWireError(wire wireError) (error, error) {
+	d := &wireDecoder{stacks: make([]Stack, len(wire.Stacks))}
+	for i, frames := range wire.Stacks {
+		d.stacks[i] = &RemoteStack{Frames: frames}
+	}
+	return d.chain(wire.Chain)
+}
+
+func (d *wireDecoder) chain(nodes []wireNode) (error, error) {
+	if d.depth++; d.depth > maxWireDepth {
+		return nil, errWireTooDeep
+	}
+	defer func() { d.depth-- }()
+	var err error
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		if node.Stack < 0 || node.Stack > len(d.stacks) {
+			return nil, fmt.Errorf("errors: decode: stack %d out of range", node.Stack)
+		}
+		var stack Stack
+		if node.Stack > 0 {
+			stack = d.stacks[node.Stack-1]
+		}
+		next, e := d.node(node, err, stack)
+		if e != nil {
+			return nil, e
+		}
+		err = next
+	}
+	return err, nil
+}
+
+func (d *wireDecoder) node(node wireNode, cause error, stack Stack) (error, error) {
+	if node.Layer {
+		if t, ok := lookupInfoType(node.Type); ok && node.Info != nil {
+			return t.wrap(node.Info, cause, stack)
+		}
+		return &fundamental[remoteInfo]{
+			cause: cause,
+			info:  remoteInfo{Type: node.Type, Text: node.Text, Message: node.Message},
+			stack: stack,
+		}, nil
+	}
+	if node.Branches != nil {
+		errs := make([]error, len(node.Branches))
+		for i, branch := range node.Branches {
+			err, e := d.chain(branch)
+			if e != nil {
+				return nil, e
+			}
+			errs[i] = err
+		}
+		return &remoteJoinError{typ: node.Type, text: node.Text, errs: errs, stack: stack}, nil
+	}
+	return &remoteError{typ: node.Type, text: node.Text, cause: cause, stack: stack}, nil
+}
+
+// EncodeJSON encodes err to be sent to another process and rebuilt there by
+// DecodeJSON. Every error of its chain is encoded with its message and stack,
+// as symbolized frames, and the layers with their ErrorInfo when its type is
+// registered, see RegisterInfoType.
+// If err is nil, EncodeJSON returns null.
+func EncodeJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(newWireError(err))
+}
+
+// DecodeJSON rebuilds an error encoded by EncodeJSON. Its Error, GetStack and
+// GetAllErrorInfo for the registered types behave as they did in the sender,
+// with stacks decoded as RemoteStack. Foreign errors of the sender only keep
+// their message and the stack they carried, if any.
+func DecodeJSON(data []byte) (error, error) {
+	var wire *wireError
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, err
+	}
+	if wire == nil {
+		return nil, nil
+	}
+	return decodeWireError(*wire)
+}
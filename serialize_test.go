@@ -0,0 +1,178 @@
+package errors
+
+import (
+	goError "errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type unregisteredInfo struct{}
+
+func (unregisteredInfo) WhenError(cause error) string {
+	if cause != nil {
+		return "unregistered: " + cause.Error()
+	} else {
+		return "unregistered"
+	}
+}
+
+func init() {
+	RegisterInfoType[CodeInfo]()
+}
+
+var testCodeSerialized = RegisterCode(-2001, "SERIALIZED", "", 500, 13)
+
+func serializedError() error {
+	err := WithErrorInfo(errTestNotFound.Wrap(io.EOF), CodeInfo{Code: 100})
+	err = WithFields(err, "user_id", "u1")
+	err = WithErrorInfo(err, unregisteredInfo{})
+	err = fmt.Errorf("foreign: %w", err)
+	err = WithCode(err, testCodeSerialized)
+	err = Join(err, New("second"))
+	return WithMessagef(err, "msg %d", 1)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	codecs := []struct {
+		name   string
+		encode func(error) ([]byte, error)
+		decode func([]byte) (error, error)
+	}{
+		{"json", EncodeJSON, DecodeJSON},
+		{"binary", EncodeBinary, DecodeBinary},
+	}
+	for _, codec := range codecs {
+		t.Run(codec.name, func(t *testing.T) {
+			sent := serializedError()
+			data, err := codec.encode(sent)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got, err := codec.decode(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if got.Error() != sent.Error() {
+				t.Errorf("Error(): got %q, want %q", got.Error(), sent.Error())
+			}
+			if infos, want := GetAllErrorInfo[CodeInfo](got), GetAllErrorInfo[CodeInfo](sent); !reflect.DeepEqual(infos, want) {
+				t.Errorf("GetAllErrorInfo: got %v, want %v", infos, want)
+			}
+			if fields := GetFields(got); !reflect.DeepEqual(fields, GetFields(sent)) {
+				t.Errorf("GetFields: got %v", fields)
+			}
+			if !IsCode(got, testCodeSerialized) || !goError.Is(got, errTestNotFound) {
+				t.Errorf("IsCode or Is: got false")
+			}
+			if goError.Is(got, io.EOF) {
+				t.Errorf("Is: foreign errors of the sender matched")
+			}
+
+			stack, ok := GetStack(got)
+			if !ok {
+				t.Fatal("GetStack: no stack")
+			}
+			if _, ok := stack.(*RemoteStack); !ok {
+				t.Errorf("GetStack: got %T, want *RemoteStack", stack)
+			}
+			want, _ := GetStack(sent)
+			if !sameFrames(stack.StackTrace(), want.StackTrace()) {
+				t.Errorf("GetStack: got %v, want %v", stack.StackTrace(), want.StackTrace())
+			}
+
+			branches := Cause(got)
+			if branches.Error() != "EOF" {
+				t.Errorf("Cause: got %q", branches.Error())
+			}
+		})
+	}
+}
+
+func sameFrames(got, want []Frame) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].FullFuncName() != want[i].FullFuncName() || got[i].File() != want[i].File() || got[i].Line() != want[i].Line() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEncodeDecodeNil(t *testing.T) {
+	data, _ := EncodeJSON(nil)
+	if err, e := DecodeJSON(data); err != nil || e != nil {
+		t.Errorf("DecodeJSON(null): got %v %v", err, e)
+	}
+	data, _ = EncodeBinary(nil)
+	if err, e := DecodeBinary(data); err != nil || e != nil {
+		t.Errorf("DecodeBinary(nil): got %v %v", err, e)
+	}
+}
+
+func TestDecodeBinaryCorrupted(t *testing.T) {
+	data, _ := EncodeBinary(serializedError())
+	// a chain of one node whose single branch nests again, past the limit
+	deep := []byte(binaryMagic + "\x01\x00")
+	for i := 0; i <= maxWireDepth; i++ {
+		deep = append(deep, 1, 0, 0, 0, 0, 0, 0, 1)
+	}
+	deep = append(deep, 0)
+	for _, corrupted := range [][]byte{
+		[]byte("not an error"),
+		data[:len(data)/2],
+		append(append([]byte{}, data[:2]...), 9),
+		deep,
+	} {
+		if _, err := DecodeBinary(corrupted); err == nil {
+			t.Errorf("DecodeBinary(%q): got no error", corrupted)
+		}
+	}
+}
+
+func TestRegisterInfoTypeTwice(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("RegisterInfoType twice: got no panic")
+		}
+	}()
+	RegisterInfoType[CodeInfo]()
+}
+
+func TestDecodeJSONTooDeep(t *testing.T) {
+	node := wireNode{Type: "x"}
+	for i := 0; i < maxWireDepth; i++ {
+		node = wireNode{Type: "x", Branches: [][]wireNode{{node}}}
+	}
+	d := &wireDecoder{}
+	if _, err := d.chain([]wireNode{node}); err != errWireTooDeep {
+		t.Errorf("got %v, want %v", err, errWireTooDeep)
+	}
+}
+
+func TestEncodeDecodeLayerText(t *testing.T) {
+	sent := WithMessage(WithErrorInfo(WithMessage(io.EOF, "inner"), unregisteredInfo{}), "outer")
+	// each layer once, followed by the stack
+	want := "outer\nunregistered\ninner\nEOF\n"
+	for name, codec := range map[string]struct {
+		encode func(error) ([]byte, error)
+		decode func([]byte) (error, error)
+	}{
+		"json":   {EncodeJSON, DecodeJSON},
+		"binary": {EncodeBinary, DecodeBinary},
+	} {
+		data, _ := codec.encode(sent)
+		got, err := codec.decode(data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if got := fmt.Sprintf("%+v", got); !strings.HasPrefix(got, want) {
+			t.Errorf("%s: %%+v: got\n%s\nwant it to start with\n%s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Severity tells how serious an error is, and so the level it is logged at.
+type Severity int
+
+const (
+	SeverityDebug Severity = iota
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = []string{"debug", "info", "warn", "error", "critical"}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Level returns the slog level errors of severity s are logged at.
+// SeverityCritical is logged above slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityError:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if strings.EqualFold(string(text), name) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("errors: unknown severity %q", text)
+}
+
+// WhenError leaves the message of cause unchanged.
+func (s Severity) WhenError(cause error) string {
+	if cause != nil {
+		return cause.Error()
+	} else {
+		return "severity " + s.String()
+	}
+}
+
+// WithSeverity annotates err with its severity.
+// If err is nil, WithSeverity returns nil.
+func WithSeverity(err error, severity Severity) error {
+	if err == nil {
+		return nil
+	}
+	return withErrorInfo(err, severity, loadCapturePolicy())
+}
+
+// SeverityRule tells GetSeverity which Severity wins when several are
+// attached to an error.
+type SeverityRule int
+
+const (
+	// SeverityMax picks the most serious Severity of the chain.
+	SeverityMax SeverityRule = iota
+	// SeverityOutermost picks the Severity closest to the caller, so code
+	// handling an error can lower the severity given deeper.
+	SeverityOutermost
+)
+
+var severityRule atomic.Int32
+
+// SetSeverityRule sets the rule used by GetSeverity, SeverityMax by default.
+func SetSeverityRule(rule SeverityRule) {
+	severityRule.Store(int32(rule))
+}
+
+// GetSeverity returns the Severity attached to err, following the rule set
+// by SetSeverityRule when several are.
+func GetSeverity(err error) (Severity, bool) {
+	if SeverityRule(severityRule.Load()) == SeverityOutermost {
+		return GetErrorInfo[Severity](err)
+	}
+	all := GetAllErrorInfo[Severity](err)
+	if len(all) == 0 {
+		return 0, false
+	}
+	res := all[0]
+	for _, s := range all[1:] {
+		res = max(res, s)
+	}
+	return res, true
+}
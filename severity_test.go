@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestGetSeverity(t *testing.T) {
+	err := WithSeverity(io.EOF, SeverityCritical)
+	err = WithMessage(err, "msg")
+	err = WithSeverity(err, SeverityInfo)
+
+	if got, ok := GetSeverity(err); got != SeverityCritical || !ok {
+		t.Errorf("max: got %v %v", got, ok)
+	}
+
+	SetSeverityRule(SeverityOutermost)
+	defer SetSeverityRule(SeverityMax)
+	if got, ok := GetSeverity(err); got != SeverityInfo || !ok {
+		t.Errorf("outermost: got %v %v", got, ok)
+	}
+	if got, ok := GetSeverity(New("whoops")); ok {
+		t.Errorf("none: got %v %v", got, ok)
+	}
+	if err.Error() != "msg: EOF" {
+		t.Errorf("Error: got %q", err.Error())
+	}
+	if WithSeverity(nil, SeverityWarn) != nil {
+		t.Error("WithSeverity(nil) is not nil")
+	}
+}
+
+func TestSeverityText(t *testing.T) {
+	data, err := json.Marshal(SeverityWarn)
+	if err != nil || string(data) != `"warn"` {
+		t.Fatalf("Marshal: got %s %v", data, err)
+	}
+	var s Severity
+	if err := json.Unmarshal([]byte(`"CRITICAL"`), &s); err != nil || s != SeverityCritical {
+		t.Errorf("Unmarshal: got %v %v", s, err)
+	}
+	if err := json.Unmarshal([]byte(`"fatal"`), &s); err == nil {
+		t.Error("Unmarshal fatal: no error")
+	}
+	if got := Severity(7).String(); got != "severity(7)" {
+		t.Errorf("String: got %q", got)
+	}
+}
+
+func TestSlogHandlerSeverity(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []any
+		level string
+	}{
+		{"none", []any{slog.Any("err", New("whoops"))}, "ERROR"},
+		{"lowered", []any{slog.Any("err", WithSeverity(io.EOF, SeverityWarn))}, "WARN"},
+		{"raised", []any{slog.Any("err", WithSeverity(io.EOF, SeverityCritical))}, "ERROR+4"},
+		{"max", []any{
+			slog.Any("a", WithSeverity(io.EOF, SeverityInfo)),
+			slog.Group("g", slog.Any("b", WithSeverity(io.EOF, SeverityWarn))),
+		}, "WARN"},
+	}
+	for _, tt := range tests {
+		res := logJSON(t, func(buf *bytes.Buffer) slog.Handler {
+			return NewSlogHandler(slog.NewJSONHandler(buf, nil))
+		}, tt.args...)
+		if res["level"] != tt.level {
+			t.Errorf("%s: got %v, want %s", tt.name, res["level"], tt.level)
+		}
+	}
+
+	var buf bytes.Buffer
+	slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil))).Error("failed", "err", WithSeverity(io.EOF, SeverityDebug))
+	if buf.Len() != 0 {
+		t.Errorf("debug error logged: %s", buf.String())
+	}
+}
+
+func TestSlogHandlerSeverityMinLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	// Severity does not raise the records below the minimum level
+	logger.Info("failed", "err", WithSeverity(io.EOF, SeverityCritical))
+	if buf.Len() != 0 {
+		t.Errorf("info record logged: %s", buf.String())
+	}
+
+	logger.Error("failed", "err", WithSeverity(io.EOF, SeverityWarn))
+	var res map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if res["level"] != "WARN" {
+		t.Errorf("level: got %v, want WARN", res["level"])
+	}
+}
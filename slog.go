@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer, see logValue.
+func (f *fundamental[T]) LogValue() slog.Value {
+	return logValue(f)
+}
+
+// logValue returns a group holding the message of err, its origin frame,
+// its deepest stack, its severity, the fields merged by GetFields and one
+// group per type of the other ErrorInfo attached to its tree, named after
+// the type and holding its outermost value.
+func logValue(err error) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", err.Error())}
+	if frame, ok := GetStackCause(err); ok {
+		attrs = append(attrs, slog.String("origin", frameText(frame)))
+	}
+	if stack, ok := GetStack(err); ok {
+		frames := stack.StackTrace()
+		lines := make([]string, len(frames))
+		for i, frame := range frames {
+			lines[i] = frameText(frame)
+		}
+		attrs = append(attrs, slog.Any("stack", lines))
+	}
+	if severity, ok := GetSeverity(err); ok {
+		attrs = append(attrs, slog.String("severity", severity.String()))
+	}
+	if fields := GetFields(err); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		group := make([]any, len(keys))
+		for i, key := range keys {
+			group[i] = slog.Any(key, fields[key])
+		}
+		attrs = append(attrs, slog.Group("fields", group...))
+	}
+	seen := make(map[string]bool)
+	walk(err, func(err error) bool {
+		if l, ok := err.(layer); ok {
+			// like GetErrorInfo, the outermost info of a type wins
+			if attr, ok := infoAttr(l.layerInfo()); ok && !seen[attr.Key] {
+				seen[attr.Key] = true
+				attrs = append(attrs, attr)
+			}
+		}
+		return true
+	})
+	return slog.GroupValue(attrs...)
+}
+
+// frameText formats frame as <funcname> <file>:<line>.
+func frameText(frame Frame) string {
+	return fmt.Sprintf("%s %s:%d", frame.FullFuncName(), frame.File(), frame.Line())
+}
+
+// infoAttr returns info as an attribute named after its type.
+// Structs are expanded to a group of their exported fields unless they
+// implement slog.LogValuer. The messages, stacks, fields and severities added
+// by the package itself are already part of the group built by logValue.
+func infoAttr(info ErrorInfo) (slog.Attr, bool) {
+	switch info.(type) {
+	case emptyInfo, message, Fields, Severity:
+		return slog.Attr{}, false
+	case slog.LogValuer:
+		return slog.Any(infoName(info), info), true
+	}
+	v := reflect.Indirect(reflect.ValueOf(info))
+	if v.Kind() != reflect.Struct {
+		return slog.Any(infoName(info), info), true
+	}
+	fields := make([]any, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Type().Field(i); field.IsExported() {
+			fields = append(fields, slog.Any(field.Name, v.Field(i).Interface()))
+		}
+	}
+	return slog.Group(infoName(info), fields...), true
+}
+
+// infoName returns the name of the type of info, without package
+// nor pointer indirection.
+func infoName(info ErrorInfo) string {
+	t := reflect.TypeOf(info)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
+
+type slogHandler struct {
+	handler slog.Handler
+}
+
+// NewSlogHandler returns a slog.Handler expanding every error attribute
+// carrying a stack the same way LogValue does, including errors wrapped
+// by foreign ones such as fmt.Errorf, before passing records to handler.
+func NewSlogHandler(handler slog.Handler) slog.Handler {
+	return &slogHandler{handler: handler}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle logs r at the level of the most serious Severity of its errors, if
+// any, so expected errors do not page anyone even when logged with Error.
+// A Severity can lower the level of any record, but only raise the level of
+// the records the handler is already enabled for: slog.Logger drops the
+// others before Handle, checking Enabled with the level of the call.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level, found := r.Level, false
+	r.Attrs(func(attr slog.Attr) bool {
+		if severity, ok := attrSeverity(attr); ok && (!found || severity.Level() > level) {
+			level, found = severity.Level(), true
+		}
+		return true
+	})
+	if level != r.Level && !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+	expanded := slog.NewRecord(r.Time, level, r.Message, r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expandAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, expanded)
+}
+
+// attrSeverity returns the most serious Severity of the errors of attr.
+func attrSeverity(attr slog.Attr) (res Severity, found bool) {
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		for _, a := range attr.Value.Group() {
+			if severity, ok := attrSeverity(a); ok && (!found || severity > res) {
+				res, found = severity, true
+			}
+		}
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := attr.Value.Any().(error); ok {
+			return GetSeverity(err)
+		}
+	}
+	return res, found
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		expanded[i] = expandAttr(attr)
+	}
+	return &slogHandler{handler: h.handler.WithAttrs(expanded)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{handler: h.handler.WithGroup(name)}
+}
+
+func expandAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, len(group))
+		for i, a := range group {
+			expanded[i] = expandAttr(a)
+		}
+		attr.Value = slog.GroupValue(expanded...)
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok && err != nil {
+			if _, ok := GetStack(err); ok {
+				attr.Value = logValue(err)
+			}
+		}
+	}
+	return attr
+}
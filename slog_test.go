@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	goError "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func logJSON(t *testing.T, handler func(*bytes.Buffer) slog.Handler, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	slog.New(handler(&buf)).Error("failed", args...)
+	var res map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("Unmarshal %s: %v", buf.Bytes(), err)
+	}
+	return res
+}
+
+func jsonHandler(buf *bytes.Buffer) slog.Handler {
+	return slog.NewJSONHandler(buf, nil)
+}
+
+func TestLogValue(t *testing.T) {
+	err := WithErrorInfo(goError.New("whoops"), CodeInfo{Code: 100})
+	err = WithMessage(err, "msg")
+
+	res := logJSON(t, jsonHandler, slog.Any("err", err))
+	got, ok := res["err"].(map[string]any)
+	if !ok {
+		t.Fatalf("err: got %v", res["err"])
+	}
+	if got["msg"] != "msg: whoops" {
+		t.Errorf("msg: got %v", got["msg"])
+	}
+	if origin, _ := got["origin"].(string); !strings.HasPrefix(origin, "github.com/mochi-c/errors.TestLogValue ") {
+		t.Errorf("origin: got %v", got["origin"])
+	}
+	if stack, _ := got["stack"].([]any); len(stack) == 0 || stack[0] != got["origin"] {
+		t.Errorf("stack: got %v", got["stack"])
+	}
+	if info, _ := got["CodeInfo"].(map[string]any); info["Code"] != float64(100) {
+		t.Errorf("CodeInfo: got %v", got["CodeInfo"])
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	cause := WithErrorInfo(goError.New("whoops"), CodeInfo{Code: 100})
+	err := fmt.Errorf("wrapped: %w", cause)
+
+	res := logJSON(t, jsonHandler, slog.Any("err", err))
+	if res["err"] != "wrapped: whoops" {
+		t.Errorf("without handler: got %v", res["err"])
+	}
+
+	wrapped := func(buf *bytes.Buffer) slog.Handler {
+		return NewSlogHandler(slog.NewJSONHandler(buf, nil)).WithAttrs([]slog.Attr{slog.Any("base", cause)})
+	}
+	res = logJSON(t, wrapped, slog.Group("req", slog.Any("err", err)), slog.Any("plain", goError.New("plain")))
+	got, _ := res["req"].(map[string]any)["err"].(map[string]any)
+	if got["msg"] != "wrapped: whoops" {
+		t.Errorf("msg: got %v", got["msg"])
+	}
+	if info, _ := got["CodeInfo"].(map[string]any); info["Code"] != float64(100) {
+		t.Errorf("CodeInfo: got %v", got["CodeInfo"])
+	}
+	if base, _ := res["base"].(map[string]any); base["msg"] != "whoops" {
+		t.Errorf("base: got %v", res["base"])
+	}
+	if res["plain"] != "plain" {
+		t.Errorf("plain: got %v", res["plain"])
+	}
+}
+
+func TestLogValueSameType(t *testing.T) {
+	err := WithErrorInfo(goError.New("whoops"), CodeInfo{Code: 100})
+	err = WithErrorInfo(err, CodeInfo{Code: 101})
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	if n := strings.Count(buf.String(), `"CodeInfo"`); n != 1 {
+		t.Errorf("CodeInfo keys: got %d in %s", n, buf.String())
+	}
+	res := logJSON(t, jsonHandler, slog.Any("err", err))
+	if info, _ := res["err"].(map[string]any)["CodeInfo"].(map[string]any); info["Code"] != float64(101) {
+		t.Errorf("CodeInfo: got %v", info)
+	}
+}
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"fmt"
+	"io"
 	"runtime"
+	"strings"
 )
 
 type Stack interface {
@@ -10,37 +12,163 @@ type Stack interface {
 	StackSource() Frame
 }
 
+// StackTrace is the frames of a stack, innermost call first. It matches the
+// StackTrace of github.com/pkg/errors, see the pkgcompat package.
+type StackTrace []Frame
+
+// Format formats the stack of Frames according to the fmt.Formatter interface.
+//
+//	%s	lists source files for each Frame in the stack
+//	%v	lists the source file and line number for each Frame in the stack
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+v   Prints filename, function, and line number for each Frame in the stack.
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			for _, f := range st {
+				fmt.Fprintf(s, "\n%+v", f)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, "[")
+		for i, f := range st {
+			if i > 0 {
+				io.WriteString(s, " ")
+			}
+			fmt.Fprintf(s, "%"+string(verb), f)
+		}
+		io.WriteString(s, "]")
+	}
+}
+
 // Stack represents a stack of program counters.
 type pcStack []uintptr
 
 func (stack *pcStack) Format(st fmt.State, verb rune) {
 	switch verb {
 	case 'v':
-		for _, pc := range *stack {
-			f := pcFrame(pc)
+		for _, f := range stack.StackTrace() {
 			fmt.Fprintf(st, "\n%+v", f)
 		}
 		fmt.Fprintf(st, "\n")
 	}
 }
 
+// StackTrace expands the program counters with runtime.CallersFrames,
+// so calls inlined by the compiler appear as frames of their own.
 func (stack *pcStack) StackTrace() []Frame {
-	f := make([]Frame, len(*stack))
-	for i := 0; i < len(f); i++ {
-		f[i] = pcFrame((*stack)[i])
+	f := make([]Frame, 0, len(*stack))
+	if len(*stack) == 0 {
+		return f
+	}
+	frames := runtime.CallersFrames(*stack)
+	for {
+		frame, more := frames.Next()
+		f = append(f, newPcFrame(frame))
+		if !more {
+			break
+		}
 	}
 	return f
 }
 
 func (stack *pcStack) StackSource() Frame {
-
-	return stack.StackTrace()[0]
+	frame, _ := runtime.CallersFrames(*stack).Next()
+	return newPcFrame(frame)
 }
 
-func callers(skip int) Stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(skip, pcs[:])
-	var st pcStack = pcs[0:n]
+// callers records the stack of the caller skip frames up as policy asks,
+// or returns nil when policy disables the capture.
+func callers(skip int, policy CapturePolicy) Stack {
+	var pcs []uintptr
+	skip += policy.Skip
+	switch policy.Mode {
+	case CaptureDisabled:
+		return nil
+	case CaptureOrigin:
+		pcs = make([]uintptr, 1)
+		pcs = pcs[:runtime.Callers(skip, pcs)]
+	case CaptureFull:
+		pcs = make([]uintptr, defaultDepth)
+		for {
+			n := runtime.Callers(skip, pcs)
+			if n < len(pcs) {
+				pcs = pcs[:n]
+				break
+			}
+			pcs = make([]uintptr, 2*len(pcs))
+		}
+	default:
+		depth := policy.MaxDepth
+		if depth <= 0 {
+			depth = defaultDepth
+		}
+		pcs = make([]uintptr, depth)
+		pcs = pcs[:runtime.Callers(skip, pcs)]
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+	var st pcStack = pcs
 	return &st
 }
+
+// callerPC returns the pc of the caller skip frames up,
+// or 0 when policy disables the capture.
+func callerPC(skip int, policy CapturePolicy) uintptr {
+	if policy.Mode == CaptureDisabled {
+		return 0
+	}
+	var pc [1]uintptr
+	runtime.Callers(skip+policy.Skip, pc[:])
+	return pc[0]
+}
+
+// panicCallers records the stack of a panicking goroutine from a function it
+// deferred, skip frames up, as policy asks. The frames of the deferred
+// functions and of the runtime are dropped, so the stack starts at the frame
+// that panicked.
+func panicCallers(skip int, policy CapturePolicy) Stack {
+	if policy.Mode == CaptureDisabled {
+		return nil
+	}
+	st, ok := callers(skip+1+policy.Skip, CapturePolicy{Mode: CaptureFull}).(*pcStack)
+	if !ok {
+		return nil
+	}
+	pcs := []uintptr(*st)
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			pcs = pcs[i+1:]
+			break
+		}
+	}
+	// runtime errors such as nil dereferences panic from the runtime
+	for len(pcs) > 0 {
+		fn := runtime.FuncForPC(pcs[0] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+		pcs = pcs[1:]
+	}
+	switch policy.Mode {
+	case CaptureOrigin:
+		pcs = pcs[:min(len(pcs), 1)]
+	case CaptureDepth:
+		depth := policy.MaxDepth
+		if depth <= 0 {
+			depth = defaultDepth
+		}
+		pcs = pcs[:min(len(pcs), depth)]
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+	var trimmed pcStack = pcs
+	return &trimmed
+}
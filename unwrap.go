@@ -1,46 +1,86 @@
 package errors
 
-func Cause(err error) error {
+// walk visits err and its causes depth-first in pre-order: an error is
+// visited before its causes, and the causes of an Unwrap() []error are
+// visited from left to right, each branch down to its end before the next.
+// This is the same order used by errors.Is and errors.As.
+// walk stops as soon as visit returns false and reports whether it went
+// through the whole tree.
+func walk(err error, visit func(error) bool) bool {
 	for err != nil {
-		cause, ok := err.(unwraper)
-		if !ok {
-			break
+		if !visit(err) {
+			return false
+		}
+		switch cause := err.(type) {
+		case unwraper:
+			err = cause.Unwrap()
+		case multiUnwraper:
+			for _, branch := range cause.Unwrap() {
+				if !walk(branch, visit) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
 		}
-		err = cause.Unwrap()
-		if err == nil {
-			return cause.(error)
+	}
+	return true
+}
+
+// Cause returns the root cause of err. For an error joining several causes,
+// the root cause of its first non-nil branch is returned.
+func Cause(err error) error {
+	for err != nil {
+		switch cause := err.(type) {
+		case unwraper:
+			next := cause.Unwrap()
+			if next == nil {
+				return err
+			}
+			err = next
+		case multiUnwraper:
+			var next error
+			for _, branch := range cause.Unwrap() {
+				if branch != nil {
+					next = branch
+					break
+				}
+			}
+			if next == nil {
+				return err
+			}
+			err = next
+		default:
+			return err
 		}
 	}
 	return err
 }
 
+// GetErrorInfo returns the first T found walking the error tree of err,
+// see walk for the visiting order.
 func GetErrorInfo[T any](err error) (res T, ok bool) {
-	for err != nil {
+	walk(err, func(err error) bool {
 		if ins, hit := err.(Fundamental[T]); hit {
-			return ins.GetErrorInfo(), true
+			res, ok = ins.GetErrorInfo(), true
+			return false
 		}
-		cause, unwrap := err.(unwraper)
-		if !unwrap {
-			break
-		}
-		err = cause.Unwrap()
-	}
+		return true
+	})
 	return res, ok
 }
 
-// GetAllErrorInfo latest with base at the first index
+// GetAllErrorInfo latest with base at the first index.
+// Branches of joined errors are listed in the order they are walked.
 func GetAllErrorInfo[T any](err error) []T {
 	res := make([]T, 0)
-	for err != nil {
+	walk(err, func(err error) bool {
 		if ins, hit := err.(Fundamental[T]); hit {
 			res = append(res, ins.GetErrorInfo())
 		}
-		cause, unwrap := err.(unwraper)
-		if !unwrap {
-			break
-		}
-		err = cause.Unwrap()
-	}
+		return true
+	})
 	return res
 }
 
@@ -53,19 +93,24 @@ func GetOriginalErrorInfo[T any](err error) (res T, ok bool) {
 	}
 }
 
-// GetStack find the deepest Stack
-func GetStack(err error) (Stack, bool) {
-	for err != nil {
+// GetStack find the deepest Stack.
+// An error built by Join carries the stack of the place it was joined at,
+// which wins over the stacks of its branches. For errors joined by
+// errors.Join or fmt.Errorf with many %w, the first branch carrying a Stack
+// wins.
+// Layers without a Stack, see CaptureDisabled, are skipped.
+func GetStack(err error) (stack Stack, ok bool) {
+	walk(err, func(err error) bool {
 		if ins, hit := err.(HasStack); hit {
-			return ins.GetStack(), true
-		}
-		cause, unwrap := err.(unwraper)
-		if !unwrap {
-			break
+			// layers created while the capture was disabled have no stack
+			if stack = ins.GetStack(); stack != nil {
+				ok = true
+				return false
+			}
 		}
-		err = cause.Unwrap()
-	}
-	return nil, false
+		return true
+	})
+	return stack, ok
 }
 
 func GetStackCause(err error) (frame Frame, ok bool) {
@@ -78,3 +123,19 @@ func GetStackCause(err error) (frame Frame, ok bool) {
 	}
 	return frame, false
 }
+
+// GetWrapTrace returns the frames of the calls that created every layer of
+// err, outermost first: the path the error took back up from its origin.
+// Layers created while the capture was disabled are skipped.
+func GetWrapTrace(err error) []Frame {
+	res := make([]Frame, 0)
+	walk(err, func(err error) bool {
+		if ins, hit := err.(HasWrapSite); hit {
+			if site := ins.WrapSite(); site != nil {
+				res = append(res, site)
+			}
+		}
+		return true
+	})
+	return res
+}
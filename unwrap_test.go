@@ -0,0 +1,93 @@
+package errors
+
+import (
+	goError "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestCauseMultiError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"join", goError.Join(WithMessage(io.EOF, "first"), io.ErrUnexpectedEOF), io.EOF},
+		{"errorf", fmt.Errorf("%w and %w", io.ErrClosedPipe, io.EOF), io.ErrClosedPipe},
+		{"wrapped join", WithMessage(goError.Join(nil, io.EOF), "msg"), io.EOF},
+	}
+	for _, tt := range tests {
+		if got := Cause(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetErrorInfoMultiError(t *testing.T) {
+	first := WithErrorInfo(goError.New("first"), CodeInfo{Code: 100})
+	second := WithErrorInfo(goError.New("second"), CodeInfo{Code: 200})
+	second = WithErrorInfo(second, CodeInfo{Code: 201})
+	err := WithMessage(fmt.Errorf("%w, %w", goError.Join(first), second), "msg")
+
+	info, ok := GetErrorInfo[CodeInfo](err)
+	if !ok || info.Code != 100 {
+		t.Errorf("GetErrorInfo: got %v %v, want 100 true", info.Code, ok)
+	}
+
+	infos := GetAllErrorInfo[CodeInfo](err)
+	if len(infos) != 3 || infos[0].Code != 100 || infos[1].Code != 201 || infos[2].Code != 200 {
+		t.Errorf("GetAllErrorInfo: got %v", infos)
+	}
+
+	original, ok := GetOriginalErrorInfo[CodeInfo](err)
+	if !ok || original.Code != 200 {
+		t.Errorf("GetOriginalErrorInfo: got %v %v, want 200 true", original.Code, ok)
+	}
+}
+
+func TestGetStackMultiError(t *testing.T) {
+	withStack := Wrap(io.EOF)
+	err := goError.Join(io.ErrUnexpectedEOF, withStack)
+
+	stack, ok := GetStack(err)
+	if !ok {
+		t.Fatal("GetStack: no stack found in second branch")
+	}
+	want, _ := GetStack(withStack)
+	if stack != want {
+		t.Errorf("GetStack: got a stack from another layer")
+	}
+
+	if _, ok := GetStack(goError.Join(io.EOF, io.ErrUnexpectedEOF)); ok {
+		t.Errorf("GetStack: found a stack in errors without one")
+	}
+}
+
+func TestGetWrapTrace(t *testing.T) {
+	err := New("whoops")
+	err = Wrap(err)
+	err = fmt.Errorf("foreign: %w", err)
+	err = WithMessage(err, "msg")
+
+	trace := GetWrapTrace(err)
+	lines := []int{71, 69, 68}
+	if len(trace) != len(lines) {
+		t.Fatalf("got %d frames, want %d", len(trace), len(lines))
+	}
+	for i, line := range lines {
+		if trace[i].FuncName() != "TestGetWrapTrace" || trace[i].Line() != line {
+			t.Errorf("frame %d: got %s:%d, want TestGetWrapTrace:%d", i, trace[i].FuncName(), trace[i].Line(), line)
+		}
+	}
+
+	site := err.(HasWrapSite).WrapSite()
+	if site.Line() != 71 {
+		t.Errorf("WrapSite: got line %d, want 71", site.Line())
+	}
+
+	disabled := CapturePolicy{Mode: CaptureDisabled}
+	if trace := GetWrapTrace(disabled.WithMessage(goError.New("whoops"), "msg")); len(trace) != 0 {
+		t.Errorf("got %d frames while disabled, want 0", len(trace))
+	}
+}
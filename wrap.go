@@ -12,7 +12,7 @@ func (emptyInfo) WhenError(cause error) string {
 
 func Wrap(err error) error {
 	if err != nil {
-		return withErrorInfo(err, emptyInfo{})
+		return withErrorInfo(err, emptyInfo{}, loadCapturePolicy())
 	} else {
 		return nil
 	}